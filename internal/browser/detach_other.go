@@ -0,0 +1,8 @@
+//go:build !unix
+
+package browser
+
+import "os/exec"
+
+// detach is a no-op on platforms without sessions.
+func detach(*exec.Cmd) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package browser
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in a new session, so it neither receives signals sent to
+// mdp's process group nor exits when the terminal is closed.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
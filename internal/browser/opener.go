@@ -1,20 +1,172 @@
 // Package browser provides functionality to open files in a browser.
 package browser
 
-import "os/exec"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxStderr limits how much of the command's stderr is included in errors.
+const maxStderr = 1024
+
+// Placeholders replaced in browser command arguments.
+const (
+	PlaceholderPath  = "{path}"
+	PlaceholderURL   = "{url}"
+	PlaceholderTitle = "{title}"
+)
+
+// Target describes the page to open.
+type Target struct {
+	// Path is the generated HTML file.
+	Path string
+	// URL is the address of the page. It defaults to the file URL of Path.
+	URL string
+	// Title is the document title.
+	Title string
+}
 
 // Opener opens files using a specified browser command.
 type Opener struct {
-	command string
+	command []string
+	timeout time.Duration
+}
+
+// Option configures an Opener.
+type Option func(*Opener)
+
+// WithTimeout sets how long Open waits for the browser command to exit.
+// A command still running after the timeout is left running in the
+// background and Open returns without error. Zero means Open does not wait.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Opener) {
+		o.timeout = timeout
+	}
+}
+
+// NewOpener creates a new Opener with the given browser command, split into
+// the program and its arguments. The arguments may contain the {path},
+// {url} and {title} placeholders; if none contains {path} or {url}, the
+// target URL, or the path when the target has no URL, is appended.
+func NewOpener(command []string, opts ...Option) *Opener {
+	o := &Opener{command: command}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Open starts the browser command detached from mdp and waits up to the
+// configured timeout for it to exit. If it fails within that time, the
+// error includes what it wrote to stderr.
+func (o *Opener) Open(target Target) error {
+	args := o.Args(target)
+	if len(args) == 0 {
+		return errors.New("no browser command configured")
+	}
+
+	// Stderr goes to a file rather than a pipe, so a browser that keeps
+	// running after mdp exits does not get SIGPIPE when it writes to it.
+	stderr, err := os.CreateTemp("", "mdp-browser-*.log")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stderr.Close()
+		_ = os.Remove(stderr.Name())
+	}()
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // G204: command is from trusted config
+	cmd.Stderr = stderr
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	if o.timeout == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(o.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return commandError(args[0], err, stderr)
+		}
+		return nil
+	case <-timer.C:
+		return nil
+	}
 }
 
-// NewOpener creates a new Opener with the given browser command.
-func NewOpener(command string) *Opener {
-	return &Opener{command: command}
+// commandError describes a failed browser command, including the end of
+// its stderr output.
+func commandError(name string, err error, stderr *os.File) error {
+	info, statErr := stderr.Stat()
+	if statErr != nil || info.Size() == 0 {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	offset := max(info.Size()-maxStderr, 0)
+	buf := make([]byte, info.Size()-offset)
+	n, _ := stderr.ReadAt(buf, offset)
+	output := strings.TrimSpace(string(buf[:n]))
+	if output == "" {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return fmt.Errorf("%s: %w: %s", name, err, output)
+}
+
+// Args returns the command line that opens target, with placeholders
+// replaced.
+func (o *Opener) Args(target Target) []string {
+	if len(o.command) == 0 {
+		return nil
+	}
+
+	// A command without a {path} or {url} placeholder gets the target URL
+	// when one is given, such as a page served by the daemon, and the path
+	// otherwise.
+	appended := target.URL
+	if target.URL == "" {
+		target.URL = FileURL(target.Path)
+		appended = target.Path
+	}
+	replacer := strings.NewReplacer(
+		PlaceholderPath, target.Path,
+		PlaceholderURL, target.URL,
+		PlaceholderTitle, target.Title,
+	)
+
+	args := make([]string, 0, len(o.command)+1)
+	hasTarget := false
+	for _, arg := range o.command {
+		if strings.Contains(arg, PlaceholderPath) || strings.Contains(arg, PlaceholderURL) {
+			hasTarget = true
+		}
+		args = append(args, replacer.Replace(arg))
+	}
+	if !hasTarget {
+		args = append(args, appended)
+	}
+	return args
 }
 
-// Open opens the specified file in the browser.
-func (o *Opener) Open(filePath string) error {
-	cmd := exec.Command(o.command, filePath) //nolint:gosec // G204: command is from trusted config
-	return cmd.Run()
+// FileURL returns the file:// URL of path.
+func FileURL(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
 }
@@ -1,26 +1,143 @@
 package browser
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestNewOpener(t *testing.T) {
-	opener := NewOpener("firefox")
-	if opener.command != "firefox" {
-		t.Errorf("NewOpener().command = %q, want %q", opener.command, "firefox")
+	opener := NewOpener([]string{"firefox"})
+	if !slices.Equal(opener.command, []string{"firefox"}) {
+		t.Errorf("NewOpener().command = %q, want %q", opener.command, []string{"firefox"})
 	}
 }
 
 func TestOpen(t *testing.T) {
-	opener := NewOpener("echo")
-	err := opener.Open("/path/to/file.html")
+	opener := NewOpener([]string{"echo"})
+	err := opener.Open(Target{Path: "/path/to/file.html"})
 	if err != nil {
 		t.Errorf("Open() error: %v", err)
 	}
 }
 
 func TestOpen_InvalidCommand(t *testing.T) {
-	opener := NewOpener("nonexistent-command-12345")
-	err := opener.Open("/path/to/file.html")
+	opener := NewOpener([]string{"nonexistent-command-12345"})
+	err := opener.Open(Target{Path: "/path/to/file.html"})
 	if err == nil {
 		t.Error("Open() should return error for invalid command")
 	}
 }
+
+func TestOpen_EmptyCommand(t *testing.T) {
+	opener := NewOpener(nil)
+	err := opener.Open(Target{Path: "/path/to/file.html"})
+	if err == nil {
+		t.Error("Open() should return error for empty command")
+	}
+}
+
+func TestOpen_Timeout(t *testing.T) {
+	t.Run("reports failure with stderr", func(t *testing.T) {
+		opener := NewOpener([]string{"sh", "-c", "echo 'cannot open display' >&2; exit 3", "sh", "{path}"}, WithTimeout(5*time.Second))
+		err := opener.Open(Target{Path: "/path/to/file.html"})
+		if err == nil {
+			t.Fatal("Open() should return error when the command fails")
+		}
+		if !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "cannot open display") {
+			t.Errorf("Open() error = %q, want exit status and stderr", err)
+		}
+	})
+
+	t.Run("returns after timeout while command keeps running", func(t *testing.T) {
+		opener := NewOpener([]string{"sh", "-c", "sleep 5", "sh", "{path}"}, WithTimeout(50*time.Millisecond))
+		start := time.Now()
+		if err := opener.Open(Target{Path: "/path/to/file.html"}); err != nil {
+			t.Errorf("Open() error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Open() took %v, want it to return after the timeout", elapsed)
+		}
+	})
+
+	t.Run("does not wait without timeout", func(t *testing.T) {
+		opener := NewOpener([]string{"sh", "-c", "sleep 5; exit 1", "sh", "{path}"})
+		start := time.Now()
+		if err := opener.Open(Target{Path: "/path/to/file.html"}); err != nil {
+			t.Errorf("Open() error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Open() took %v, want it to return immediately", elapsed)
+		}
+	})
+}
+
+func TestArgs(t *testing.T) {
+	target := Target{Path: "/tmp/my docs/index.html", Title: "Design Notes"}
+
+	tests := []struct {
+		name    string
+		command []string
+		target  Target
+		want    []string
+	}{
+		{
+			name:    "appends path without placeholders",
+			command: []string{"firefox", "--new-window"},
+			target:  target,
+			want:    []string{"firefox", "--new-window", "/tmp/my docs/index.html"},
+		},
+		{
+			name:    "replaces path placeholder",
+			command: []string{"open", "-a", "Safari", "{path}"},
+			target:  target,
+			want:    []string{"open", "-a", "Safari", "/tmp/my docs/index.html"},
+		},
+		{
+			name:    "replaces url placeholder with file URL",
+			command: []string{"chromium", "--app={url}"},
+			target:  target,
+			want:    []string{"chromium", "--app=file:///tmp/my%20docs/index.html"},
+		},
+		{
+			name:    "uses target URL when set",
+			command: []string{"firefox", "{url}"},
+			target:  Target{Path: "/tmp/index.html", URL: "http://127.0.0.1:8080/index.html"},
+			want:    []string{"firefox", "http://127.0.0.1:8080/index.html"},
+		},
+		{
+			name:    "appends target URL without placeholders",
+			command: []string{"firefox"},
+			target:  Target{Path: "/tmp/index.html", URL: "http://127.0.0.1:8080/index.html"},
+			want:    []string{"firefox", "http://127.0.0.1:8080/index.html"},
+		},
+		{
+			name:    "replaces title placeholder",
+			command: []string{"notify-send", "{title}", "{path}"},
+			target:  target,
+			want:    []string{"notify-send", "Design Notes", "/tmp/my docs/index.html"},
+		},
+		{
+			name:    "appends path when only title placeholder",
+			command: []string{"firefox", "--name={title}"},
+			target:  target,
+			want:    []string{"firefox", "--name=Design Notes", "/tmp/my docs/index.html"},
+		},
+		{
+			name:    "empty command",
+			command: nil,
+			target:  target,
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewOpener(tt.command).Args(tt.target)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,21 +13,39 @@ var version = "dev"
 var errHelp = errors.New("help requested")
 
 type parsedArgs struct {
-	configPath  string
-	filePath    string
-	showList    bool
-	showVersion bool
-	watchMode   bool
+	browserCommand  string
+	configPath      string
+	filePath        string
+	list            listOptions
+	noDaemon        bool
+	noProjectConfig bool
+	outputDir       string
+	printMode       bool
+	profile         string
+	showList        bool
+	showVersion     bool
+	themeName       string
+	watchMode       bool
 }
 
 func parseArgs(args []string) (*parsedArgs, error) {
 	fs := flag.NewFlagSet("mdp", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
+	browserCommand := fs.String("browser", "", "browser command, overriding config")
 	configPath := fs.String("config", "", "path to config file")
 	showHelp := fs.Bool("help", false, "show help message")
+	outputDir := fs.String("output-dir", "", "output directory, overriding config")
+	noDaemon := fs.Bool("no-daemon", false, "do not hand the file to a running daemon")
+	noProjectConfig := fs.Bool("no-project-config", false, "ignore .mdp.yaml files in the document's directory tree")
 	showList := fs.Bool("list", false, "list generated files")
+	listFormat := fs.String("format", "", "list format: table, json or tsv")
+	listSort := fs.String("sort", "", "list order: time, source, title or output")
+	listUnder := fs.String("under", "", "list only previews of files under the given directory")
+	printMode := fs.Bool("print", false, "render for printing")
+	profile := fs.String("profile", "", "config profile to use")
 	showVersion := fs.Bool("version", false, "show version")
+	themeName := fs.String("theme", "", "theme to use regardless of front-matter")
 	watchMode := fs.Bool("watch", false, "watch for file changes")
 
 	if err := fs.Parse(args); err != nil {
@@ -48,19 +66,38 @@ func parseArgs(args []string) (*parsedArgs, error) {
 	}
 
 	if *showList {
+		list := listOptions{format: *listFormat, sort: *listSort, under: *listUnder}
+		if err := list.validate(); err != nil {
+			return nil, err
+		}
 		return &parsedArgs{
-			configPath: *configPath,
-			showList:   true,
+			configPath:      *configPath,
+			list:            list,
+			noProjectConfig: *noProjectConfig,
+			outputDir:       *outputDir,
+			profile:         *profile,
+			showList:        true,
 		}, nil
 	}
 
+	if *listFormat != "" || *listSort != "" || *listUnder != "" {
+		return nil, errors.New("--format, --sort and --under require --list")
+	}
+
 	if fs.NArg() == 0 {
 		return nil, errors.New("markdown file is required")
 	}
 
 	return &parsedArgs{
-		configPath: *configPath,
-		filePath:   fs.Arg(0),
-		watchMode:  *watchMode,
+		browserCommand:  *browserCommand,
+		configPath:      *configPath,
+		filePath:        fs.Arg(0),
+		noDaemon:        *noDaemon,
+		noProjectConfig: *noProjectConfig,
+		outputDir:       *outputDir,
+		printMode:       *printMode,
+		profile:         *profile,
+		themeName:       *themeName,
+		watchMode:       *watchMode,
 	}, nil
 }
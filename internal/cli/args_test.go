@@ -95,6 +95,86 @@ func TestParseArgs(t *testing.T) {
 				watchMode:  true,
 			},
 		},
+		{
+			name: "theme flag",
+			args: []string{"--theme", "slides", "test.md"},
+			wantArgs: &parsedArgs{
+				filePath:  "test.md",
+				themeName: "slides",
+			},
+		},
+		{
+			name: "print flag",
+			args: []string{"--print", "test.md"},
+			wantArgs: &parsedArgs{
+				filePath:  "test.md",
+				printMode: true,
+			},
+		},
+		{
+			name: "no-project-config flag",
+			args: []string{"--no-project-config", "test.md"},
+			wantArgs: &parsedArgs{
+				filePath:        "test.md",
+				noProjectConfig: true,
+			},
+		},
+		{
+			name: "config override flags",
+			args: []string{"--output-dir", "/tmp/out", "--browser", "firefox --new-window", "test.md"},
+			wantArgs: &parsedArgs{
+				browserCommand: "firefox --new-window",
+				filePath:       "test.md",
+				outputDir:      "/tmp/out",
+			},
+		},
+		{
+			name: "list flag with output-dir",
+			args: []string{"--list", "--output-dir", "/tmp/out"},
+			wantArgs: &parsedArgs{
+				outputDir: "/tmp/out",
+				showList:  true,
+			},
+		},
+		{
+			name: "list flag with format, sort and under",
+			args: []string{"--list", "--format", "json", "--sort", "title", "--under", "/src"},
+			wantArgs: &parsedArgs{
+				list:     listOptions{format: "json", sort: "title", under: "/src"},
+				showList: true,
+			},
+		},
+		{
+			name:       "list flag with invalid format",
+			args:       []string{"--list", "--format", "xml"},
+			wantErrMsg: `invalid format "xml"`,
+		},
+		{
+			name:       "list flag with invalid sort",
+			args:       []string{"--list", "--sort", "size"},
+			wantErrMsg: `invalid sort "size"`,
+		},
+		{
+			name:       "format flag without list",
+			args:       []string{"--format", "json", "test.md"},
+			wantErrMsg: "require --list",
+		},
+		{
+			name: "no-daemon flag",
+			args: []string{"--no-daemon", "test.md"},
+			wantArgs: &parsedArgs{
+				filePath: "test.md",
+				noDaemon: true,
+			},
+		},
+		{
+			name: "profile flag",
+			args: []string{"--profile", "review", "test.md"},
+			wantArgs: &parsedArgs{
+				filePath: "test.md",
+				profile:  "review",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,6 +194,12 @@ func TestParseArgs(t *testing.T) {
 				t.Errorf("parseArgs() unexpected error = %v", err)
 				return
 			}
+			if got.browserCommand != tt.wantArgs.browserCommand {
+				t.Errorf("parseArgs() browserCommand = %v, want %v", got.browserCommand, tt.wantArgs.browserCommand)
+			}
+			if got.outputDir != tt.wantArgs.outputDir {
+				t.Errorf("parseArgs() outputDir = %v, want %v", got.outputDir, tt.wantArgs.outputDir)
+			}
 			if got.configPath != tt.wantArgs.configPath {
 				t.Errorf("parseArgs() configPath = %v, want %v", got.configPath, tt.wantArgs.configPath)
 			}
@@ -123,6 +209,24 @@ func TestParseArgs(t *testing.T) {
 			if got.showList != tt.wantArgs.showList {
 				t.Errorf("parseArgs() showList = %v, want %v", got.showList, tt.wantArgs.showList)
 			}
+			if got.list != tt.wantArgs.list {
+				t.Errorf("parseArgs() list = %+v, want %+v", got.list, tt.wantArgs.list)
+			}
+			if got.noDaemon != tt.wantArgs.noDaemon {
+				t.Errorf("parseArgs() noDaemon = %v, want %v", got.noDaemon, tt.wantArgs.noDaemon)
+			}
+			if got.noProjectConfig != tt.wantArgs.noProjectConfig {
+				t.Errorf("parseArgs() noProjectConfig = %v, want %v", got.noProjectConfig, tt.wantArgs.noProjectConfig)
+			}
+			if got.profile != tt.wantArgs.profile {
+				t.Errorf("parseArgs() profile = %v, want %v", got.profile, tt.wantArgs.profile)
+			}
+			if got.printMode != tt.wantArgs.printMode {
+				t.Errorf("parseArgs() printMode = %v, want %v", got.printMode, tt.wantArgs.printMode)
+			}
+			if got.themeName != tt.wantArgs.themeName {
+				t.Errorf("parseArgs() themeName = %v, want %v", got.themeName, tt.wantArgs.themeName)
+			}
 			if got.watchMode != tt.wantArgs.watchMode {
 				t.Errorf("parseArgs() watchMode = %v, want %v", got.watchMode, tt.wantArgs.watchMode)
 			}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+const cleanUsageMessage = `usage: mdp clean <selector>... [options]
+
+Removes generated previews that match all of the given selectors.
+
+Selectors:
+  --orphans               previews whose source file no longer exists
+  --older-than <age>      previews not regenerated for <age>, such as 30d, 2w or 12h
+  --under <dir>           previews of files under <dir>
+  --all                   every preview
+
+Options:
+  --dry-run               list the previews that would be removed
+  --config <config-file>  path to config file
+  --profile <name>        config profile to use
+  --output-dir <dir>      output directory, overriding config`
+
+// now is replaced in tests.
+var now = time.Now
+
+type cleanArgs struct {
+	all        bool
+	configPath string
+	dryRun     bool
+	olderThan  time.Duration
+	orphans    bool
+	outputDir  string
+	profile    string
+	under      string
+}
+
+func parseCleanArgs(args []string) (*cleanArgs, error) {
+	fs := flag.NewFlagSet("mdp clean", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	all := fs.Bool("all", false, "every preview")
+	configPath := fs.String("config", "", "path to config file")
+	dryRun := fs.Bool("dry-run", false, "list the previews that would be removed")
+	olderThan := fs.String("older-than", "", "previews not regenerated for the given age")
+	orphans := fs.Bool("orphans", false, "previews whose source file no longer exists")
+	outputDir := fs.String("output-dir", "", "output directory, overriding config")
+	profile := fs.String("profile", "", "config profile to use")
+	under := fs.String("under", "", "previews of files under the given directory")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+	if fs.NArg() != 0 {
+		return nil, errors.New("clean takes no arguments")
+	}
+
+	parsed := &cleanArgs{
+		all:        *all,
+		configPath: *configPath,
+		dryRun:     *dryRun,
+		orphans:    *orphans,
+		outputDir:  *outputDir,
+		profile:    *profile,
+	}
+
+	if *olderThan != "" {
+		age, err := parseAge(*olderThan)
+		if err != nil {
+			return nil, err
+		}
+		parsed.olderThan = age
+	}
+	if *under != "" {
+		dir, err := filepath.Abs(*under)
+		if err != nil {
+			return nil, err
+		}
+		parsed.under = dir
+	}
+
+	if !parsed.all && !parsed.orphans && parsed.olderThan == 0 && parsed.under == "" {
+		return nil, errors.New("at least one of --orphans, --older-than, --under or --all is required")
+	}
+
+	return parsed, nil
+}
+
+// parseAge parses an age such as "30d" or "2w", or any duration accepted by
+// time.ParseDuration.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q: use a number followed by d, w, h or m, such as 30d", s)
+	}
+	return age, nil
+}
+
+// matches reports whether p is selected by every selector in args.
+func (a *cleanArgs) matches(p output.Preview) bool {
+	if a.orphans && p.SourceExists {
+		return false
+	}
+	if a.olderThan > 0 && now().Sub(p.GeneratedAt) < a.olderThan {
+		return false
+	}
+	if a.under != "" && !strings.HasPrefix(p.Source, a.under+string(filepath.Separator)) {
+		return false
+	}
+	return true
+}
+
+func (c *cli) runClean(args []string) int {
+	parsed, err := parseCleanArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, cleanUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, cleanUsageMessage)
+		return 1
+	}
+
+	c.configPath = parsed.configPath
+	c.outputDir = parsed.outputDir
+	c.profile = parsed.profile
+
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	previews, err := output.Previews(cfg.OutputDir)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	removed := 0
+	exitCode := 0
+	for _, preview := range previews {
+		if !parsed.matches(preview) {
+			continue
+		}
+		if parsed.dryRun {
+			_, _ = fmt.Fprintf(c.outWriter, "Would remove: %s\n", preview.Path)
+			removed++
+			continue
+		}
+		if err := output.Remove(cfg.OutputDir, preview.Path); err != nil {
+			_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+			exitCode = 1
+			continue
+		}
+		_, _ = fmt.Fprintf(c.outWriter, "Removed: %s\n", preview.Path)
+		removed++
+	}
+
+	if parsed.dryRun {
+		_, _ = fmt.Fprintf(c.outWriter, "%d preview(s) would be removed\n", removed)
+	} else {
+		_, _ = fmt.Fprintf(c.outWriter, "%d preview(s) removed\n", removed)
+	}
+	return exitCode
+}
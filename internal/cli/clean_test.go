@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "12h", want: 12 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "d", wantErr: true},
+		{input: "-1d", wantErr: true},
+		{input: "30", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAge(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseAge(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAge(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCleanArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantErrMsg string
+	}{
+		{name: "orphans", args: []string{"--orphans"}},
+		{name: "all with dry-run", args: []string{"--all", "--dry-run"}},
+		{name: "combined selectors", args: []string{"--orphans", "--older-than", "30d", "--under", "."}},
+		{name: "no selector", args: []string{"--dry-run"}, wantErrMsg: "at least one of"},
+		{name: "invalid age", args: []string{"--older-than", "soon"}, wantErrMsg: "invalid age"},
+		{name: "argument", args: []string{"--all", "extra"}, wantErrMsg: "takes no arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCleanArgs(tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("parseCleanArgs() error = %v, want error containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("parseCleanArgs() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRunClean(t *testing.T) {
+	originalNow := now
+	defer func() { now = originalNow }()
+	now = func() time.Time { return time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	setup := func(t *testing.T) (outputDir string, paths map[string]string) {
+		t.Helper()
+		outputDir = t.TempDir()
+		srcDir := t.TempDir()
+		w := output.NewWriter(outputDir)
+
+		paths = make(map[string]string)
+		for _, name := range []string{"kept", "deleted", "old", "project/doc"} {
+			src := filepath.Join(srcDir, name+".md")
+			if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil { //nolint:gosec // G301: test directory
+				t.Fatal(err)
+			}
+			if name != "deleted" {
+				if err := os.WriteFile(src, []byte("# x"), 0644); err != nil { //nolint:gosec // G306: test file
+					t.Fatal(err)
+				}
+			}
+			path, err := w.Write(src, []byte("x"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mtime := now().Add(-24 * time.Hour)
+			if name == "old" {
+				mtime = now().Add(-60 * 24 * time.Hour)
+			}
+			key, err := output.Key(outputDir, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = output.UpdateManifest(outputDir, func(m *output.Manifest) error {
+				m.Entries[key].RenderedAt = mtime
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			paths[name] = path
+		}
+		paths["project"] = filepath.Join(srcDir, "project")
+		return outputDir, paths
+	}
+
+	tests := []struct {
+		name        string
+		args        func(paths map[string]string) []string
+		wantRemoved []string
+	}{
+		{
+			name:        "orphans",
+			args:        func(map[string]string) []string { return []string{"--orphans"} },
+			wantRemoved: []string{"deleted"},
+		},
+		{
+			name:        "older than",
+			args:        func(map[string]string) []string { return []string{"--older-than", "30d"} },
+			wantRemoved: []string{"old"},
+		},
+		{
+			name:        "under",
+			args:        func(paths map[string]string) []string { return []string{"--under", paths["project"]} },
+			wantRemoved: []string{"project/doc"},
+		},
+		{
+			name:        "all",
+			args:        func(map[string]string) []string { return []string{"--all"} },
+			wantRemoved: []string{"kept", "deleted", "old", "project/doc"},
+		},
+		{
+			name:        "selectors combine",
+			args:        func(map[string]string) []string { return []string{"--orphans", "--older-than", "30d"} },
+			wantRemoved: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir, paths := setup(t)
+
+			var stdout, stderr bytes.Buffer
+			c := &cli{outWriter: &stdout, errWriter: &stderr}
+			args := append(tt.args(paths), "--output-dir", outputDir)
+			if exitCode := c.runClean(args); exitCode != 0 {
+				t.Fatalf("runClean() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+			}
+
+			for _, name := range []string{"kept", "deleted", "old", "project/doc"} {
+				_, err := os.Stat(paths[name])
+				wantRemoved := false
+				for _, removed := range tt.wantRemoved {
+					wantRemoved = wantRemoved || removed == name
+				}
+				if wantRemoved && !os.IsNotExist(err) {
+					t.Errorf("%s was not removed", name)
+				}
+				if !wantRemoved && err != nil {
+					t.Errorf("%s was removed: %v", name, err)
+				}
+			}
+		})
+	}
+
+	t.Run("dry run removes nothing", func(t *testing.T) {
+		outputDir, paths := setup(t)
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+		if exitCode := c.runClean([]string{"--all", "--dry-run", "--output-dir", outputDir}); exitCode != 0 {
+			t.Fatalf("runClean() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "Would remove: "+paths["old"]) || !strings.Contains(stdout.String(), "4 preview(s) would be removed") {
+			t.Errorf("stdout = %q, want dry-run listing", stdout.String())
+		}
+		if _, err := os.Stat(paths["old"]); err != nil {
+			t.Errorf("dry run removed a preview: %v", err)
+		}
+	})
+}
@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/masawada/mdp/internal/browser"
 	"github.com/masawada/mdp/internal/config"
@@ -18,6 +20,73 @@ import (
 type cli struct {
 	outWriter, errWriter io.Writer
 	configPath           string
+	noProjectConfig      bool
+	noDaemon             bool
+	profile              string
+	outputDir            string
+	browserCommand       string
+	themeName            string
+	printMode            bool
+	// env, when not nil, replaces the process environment as the source of
+	// MDP_* variables, as for documents rendered by the daemon.
+	env map[string]string
+	// privateDir is the temporary output directory of a private document,
+	// or empty if the document is not private.
+	privateDir string
+}
+
+// waitForInterrupt blocks until mdp is interrupted. It is replaced in tests.
+var waitForInterrupt = func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	<-sigChan
+}
+
+// loadConfig loads the configuration, discovering project config files
+// upward from dir and applying command-line overrides.
+func (c *cli) loadConfig(dir string) (*config.Config, error) {
+	var overrides []config.Override
+	if c.outputDir != "" {
+		overrides = append(overrides, config.Override{Key: "output_dir", Value: c.outputDir, Source: "flag --output-dir"})
+	}
+	if c.browserCommand != "" {
+		overrides = append(overrides, config.Override{Key: "browser_command", Value: c.browserCommand, Source: "flag --browser"})
+	}
+	if c.themeName != "" {
+		overrides = append(overrides, config.Override{Key: "theme", Value: c.themeName, Source: "flag --theme"})
+	}
+
+	return config.LoadWithOptions(config.Options{
+		Path:            c.configPath,
+		Dir:             dir,
+		NoProjectConfig: c.noProjectConfig,
+		Profile:         c.profile,
+		Overrides:       overrides,
+		Env:             c.env,
+	})
+}
+
+// rendererOptions returns the renderer options derived from the config and
+// command-line flags.
+func (c *cli) rendererOptions(cfg *config.Config) []renderer.Option {
+	// The markdown section was validated when the config was loaded.
+	features, _ := cfg.Markdown.RendererFeatures()
+	opts := []renderer.Option{
+		renderer.WithColorScheme(cfg.ColorScheme),
+		renderer.WithColorSchemeToggle(cfg.ColorSchemeToggle),
+		renderer.WithPrint(c.printMode),
+		renderer.WithRawHTML(cfg.RawHTML),
+		renderer.WithMarkdown(features),
+		renderer.WithEmoji(cfg.Emoji.Output, cfg.Emoji.Aliases),
+	}
+	if cfg.PrintCSS != "" {
+		opts = append(opts, renderer.WithPrintCSS(cfg.PrintCSS))
+	}
+	if c.themeName != "" {
+		opts = append(opts, renderer.WithThemeOverride(c.themeName))
+	}
+	return opts
 }
 
 func (c *cli) run(filePath string, watchMode bool) int {
@@ -32,43 +101,69 @@ func (c *cli) run(filePath string, watchMode bool) int {
 		return 1
 	}
 
-	cfg, err := config.Load(c.configPath)
+	cfg, err := c.loadConfig(filepath.Dir(absPath))
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
 		return 1
 	}
 
-	r, err := renderer.NewRenderer(cfg.ConfigDir, cfg.Theme)
+	markdown, err := os.ReadFile(absPath) //nolint:gosec // G304: path is user-specified input file
 	if err != nil {
-		_, _ = fmt.Fprintf(c.errWriter, "error: failed to initialize renderer: %v\n", err)
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to read file: %v\n", err)
 		return 1
 	}
 
-	markdown, err := os.ReadFile(absPath) //nolint:gosec // G304: path is user-specified input file
+	// Private documents are never handed to the daemon, which writes to the
+	// shared output directory. Only the front-matter is checked here; the
+	// rendering is left to the daemon.
+	if !c.noDaemon && !renderer.IsPrivate(markdown) {
+		if exitCode, ok := c.handOff(absPath, cfg, watchMode); ok {
+			return exitCode
+		}
+	}
+
+	r, err := renderer.NewRenderer(cfg.ConfigDir, cfg.Theme, c.rendererOptions(cfg)...)
 	if err != nil {
-		_, _ = fmt.Fprintf(c.errWriter, "error: failed to read file: %v\n", err)
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to initialize renderer: %v\n", err)
 		return 1
 	}
 
-	html, err := r.Render(markdown)
+	doc, err := r.RenderDocument(markdown)
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errWriter, "error: failed to render: %v\n", err)
 		return 1
 	}
 
-	writer := output.NewWriter(cfg.OutputDir)
-	outputPath, err := writer.Write(absPath, html)
+	writer := newWriter(cfg)
+	if doc.Private {
+		c.removeSharedPreviews(writer, absPath)
+		dir, err := os.MkdirTemp("", "mdp-private-")
+		if err != nil {
+			_, _ = fmt.Fprintf(c.errWriter, "error: failed to create private output directory: %v\n", err)
+			return 1
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		c.privateDir = dir
+		writer = output.NewWriter(dir, output.WithLayout(output.LayoutFlat), output.WithPermissions(output.PermissionsPrivate), output.WithVersion(version))
+	}
+	result, err := writer.WriteDocument(absPath, doc.HTML, documentMetadata(doc, markdown))
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errWriter, "error: failed to write html: %v\n", err)
 		return 1
 	}
+	outputPath := result.Path
 
 	_, _ = fmt.Fprintf(c.outWriter, "Generated: %s\n", outputPath)
 
-	opener := browser.NewOpener(cfg.BrowserCommand)
-	if err := opener.Open(outputPath); err != nil {
-		_, _ = fmt.Fprintf(c.errWriter, "error: failed to open browser: %v\n", err)
-		return 1
+	if len(cfg.BrowserCommand) == 0 {
+		// Headless session or unsupported platform: leave opening to the user.
+		_, _ = fmt.Fprintln(c.outWriter, "No browser available; open the generated file manually or set browser_command")
+	} else {
+		opener := browser.NewOpener(cfg.BrowserCommand, browser.WithTimeout(time.Duration(cfg.BrowserTimeout)))
+		if err := opener.Open(browser.Target{Path: outputPath, Title: doc.Title}); err != nil {
+			_, _ = fmt.Fprintf(c.errWriter, "error: failed to open browser: %v\n", err)
+			return 1
+		}
 	}
 
 	// If watch mode is enabled, start the watch loop
@@ -78,6 +173,13 @@ func (c *cli) run(filePath string, watchMode bool) int {
 		return c.runWatchLoop(absPath, r, writer, sigChan)
 	}
 
+	if doc.Private {
+		// The page must outlive the browser loading it, so keep it until
+		// the user is done.
+		_, _ = fmt.Fprintln(c.outWriter, "Private document; the preview is removed when mdp exits (Ctrl+C to stop)")
+		waitForInterrupt()
+	}
+
 	return 0
 }
 
@@ -97,12 +199,16 @@ func (c *cli) runWatchLoop(filePath string, r *renderer.Renderer, w *output.Writ
 	for {
 		select {
 		case <-fileWatcher.Events():
-			outputPath, err := c.reconvert(filePath, r, w)
+			result, err := c.reconvert(filePath, r, w)
 			if err != nil {
 				_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
 				continue
 			}
-			_, _ = fmt.Fprintf(c.outWriter, "Regenerated: %s\n", outputPath)
+			if result.Status == output.Unchanged {
+				_, _ = fmt.Fprintln(c.outWriter, "No changes")
+				continue
+			}
+			_, _ = fmt.Fprintf(c.outWriter, "Regenerated: %s\n", result.Path)
 		case err := <-fileWatcher.Errors():
 			_, _ = fmt.Fprintf(c.errWriter, "watcher error: %v\n", err)
 		case <-sigChan:
@@ -113,43 +219,81 @@ func (c *cli) runWatchLoop(filePath string, r *renderer.Renderer, w *output.Writ
 }
 
 // reconvert reads the markdown file, renders it, and writes the output.
-func (c *cli) reconvert(filePath string, r *renderer.Renderer, w *output.Writer) (string, error) {
+func (c *cli) reconvert(filePath string, r *renderer.Renderer, w *output.Writer) (output.Result, error) {
 	// Read file
 	markdown, err := os.ReadFile(filePath) //nolint:gosec // G304: path is user-specified input file
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return output.Result{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Render markdown to HTML
-	html, err := r.Render(markdown)
+	doc, err := r.RenderDocument(markdown)
 	if err != nil {
-		return "", fmt.Errorf("failed to render: %w", err)
+		return output.Result{}, fmt.Errorf("failed to render: %w", err)
+	}
+	if doc.Private && c.privateDir == "" {
+		c.removeSharedPreviews(w, filePath)
+		return output.Result{}, errors.New("the document is now private; restart mdp to preview it in a private directory")
 	}
 
 	// Write output
-	outputPath, err := w.Write(filePath, html)
+	result, err := w.WriteDocument(filePath, doc.HTML, documentMetadata(doc, markdown))
 	if err != nil {
-		return "", fmt.Errorf("failed to write: %w", err)
+		return output.Result{}, fmt.Errorf("failed to write: %w", err)
 	}
 
-	return outputPath, nil
+	return result, nil
 }
 
-func (c *cli) listFiles() int {
-	cfg, err := config.Load(c.configPath)
+// removeSharedPreviews removes the pages that a document which is now
+// private left in the shared output directory of w.
+func (c *cli) removeSharedPreviews(w *output.Writer, filePath string) {
+	removed, err := w.RemoveSource(filePath)
+	for _, path := range removed {
+		_, _ = fmt.Fprintf(c.outWriter, "Removed shared preview: %s\n", path)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "warning: a shared preview of %s may still exist: %v\n", filePath, err)
+	}
+}
+
+// newWriter returns the writer for the output directory of cfg.
+func newWriter(cfg *config.Config) *output.Writer {
+	return output.NewWriter(cfg.OutputDir,
+		output.WithLayout(output.Layout(cfg.OutputLayout)),
+		output.WithPermissions(output.Permissions(cfg.OutputPermissions)),
+		output.WithVersion(version),
+	)
+}
+
+// documentMetadata returns what the manifest records about doc.
+func documentMetadata(doc *renderer.Document, markdown []byte) output.Metadata {
+	return output.Metadata{Title: doc.Title, Theme: doc.Theme, Print: doc.Print, Markdown: markdown}
+}
+
+// listFiles prints the previews in the output directory.
+func (c *cli) listFiles(opts listOptions) int {
+	cfg, err := c.loadConfig(".")
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
 		return 1
 	}
 
-	files, err := output.ListFiles(cfg.OutputDir)
+	previews, err := output.Previews(cfg.OutputDir)
 	if err != nil {
 		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
 		return 1
 	}
+	previews, err = opts.filter(previews)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+	opts.sortPreviews(previews)
 
-	for _, file := range files {
-		_, _ = fmt.Fprintln(c.outWriter, file)
+	if err := opts.write(c.outWriter, previews); err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
 	}
 
 	return 0
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/masawada/mdp/internal/config"
 	"github.com/masawada/mdp/internal/output"
 	"github.com/masawada/mdp/internal/renderer"
 )
@@ -66,6 +67,45 @@ func TestRun_Success(t *testing.T) {
 	}
 }
 
+func TestRun_NoBrowserAvailable(t *testing.T) {
+	for _, key := range []string{"BROWSER", "DISPLAY", "WAYLAND_DISPLAY", "WSL_DISTRO_NAME"} {
+		t.Setenv(key, "")
+	}
+	if config.DefaultBrowserCommand() != "" {
+		t.Skip("a browser is available in this environment")
+	}
+
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\n", filepath.Join(tmpDir, "output"))
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:  &stdout,
+		errWriter:  &stderr,
+		configPath: configFile,
+	}
+
+	exitCode := c.run(mdFile, false)
+	if exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Generated: ") {
+		t.Errorf("stdout should contain the generated path, got: %s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "No browser available") {
+		t.Errorf("stdout should explain that no browser was opened, got: %s", stdout.String())
+	}
+}
+
 func TestListFiles_WithFiles(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, "output")
@@ -93,7 +133,7 @@ func TestListFiles_WithFiles(t *testing.T) {
 		configPath: configFile,
 	}
 
-	exitCode := c.listFiles()
+	exitCode := c.listFiles(listOptions{})
 	if exitCode != 0 {
 		t.Errorf("listFiles() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
 	}
@@ -122,7 +162,7 @@ func TestListFiles_NoFiles(t *testing.T) {
 		configPath: configFile,
 	}
 
-	exitCode := c.listFiles()
+	exitCode := c.listFiles(listOptions{})
 	if exitCode != 0 {
 		t.Errorf("listFiles() exit code = %d, want 0", exitCode)
 	}
@@ -147,7 +187,7 @@ func TestListFiles_DirectoryNotExist(t *testing.T) {
 		configPath: configFile,
 	}
 
-	exitCode := c.listFiles()
+	exitCode := c.listFiles(listOptions{})
 	if exitCode != 1 {
 		t.Errorf("listFiles() exit code = %d, want 1", exitCode)
 	}
@@ -178,15 +218,27 @@ func TestReconvert(t *testing.T) {
 	}
 	w := output.NewWriter(outDir)
 
-	outputPath, err := c.reconvert(mdFile, r, w)
+	result, err := c.reconvert(mdFile, r, w)
 	if err != nil {
 		t.Fatalf("reconvert() returned error: %v", err)
 	}
 
 	// Verify output file exists
-	if _, err := os.Stat(outputPath); err != nil {
+	if _, err := os.Stat(result.Path); err != nil {
 		t.Errorf("output file not found: %v", err)
 	}
+	if result.Status != output.Updated {
+		t.Errorf("reconvert() status = %v, want Updated", result.Status)
+	}
+
+	// Rendering the same content again leaves the page alone
+	result, err = c.reconvert(mdFile, r, w)
+	if err != nil {
+		t.Fatalf("reconvert() returned error: %v", err)
+	}
+	if result.Status != output.Unchanged {
+		t.Errorf("second reconvert() status = %v, want Unchanged", result.Status)
+	}
 }
 
 func TestRunWatchLoop_SignalHandling(t *testing.T) {
@@ -222,3 +274,355 @@ func TestRunWatchLoop_SignalHandling(t *testing.T) {
 		t.Errorf("runWatchLoop() returned %d, want 0", exitCode)
 	}
 }
+
+func TestRun_OutputDirFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: echo\n", filepath.Join(tmpDir, "config-output"))
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	flagOutputDir := filepath.Join(tmpDir, "flag-output")
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:  &stdout,
+		errWriter:  &stderr,
+		configPath: configFile,
+		outputDir:  flagOutputDir,
+	}
+
+	exitCode := c.run(mdFile, false)
+	if exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Generated: "+flagOutputDir) {
+		t.Errorf("stdout = %q, want output under %s", stdout.String(), flagOutputDir)
+	}
+}
+
+func TestRun_BrowserCommandPlaceholders(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello World"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	argsFile := filepath.Join(tmpDir, "args.txt")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf(`output_dir: %s
+browser_command:
+  - sh
+  - -c
+  - printf '%%s|%%s' "$1" "$2" > %s
+  - sh
+  - "{title}"
+  - "{url}"
+`, filepath.Join(tmpDir, "output"), argsFile)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:  &stdout,
+		errWriter:  &stderr,
+		configPath: configFile,
+	}
+
+	exitCode := c.run(mdFile, false)
+	if exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	got, err := os.ReadFile(argsFile) //nolint:gosec // G304: test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, url, _ := strings.Cut(string(got), "|")
+	if title != "Hello World" {
+		t.Errorf("title argument = %q, want %q", title, "Hello World")
+	}
+	if !strings.HasPrefix(url, "file://") || !strings.HasSuffix(url, "/index.html") {
+		t.Errorf("url argument = %q, want file URL of the generated page", url)
+	}
+}
+
+func TestRun_RecordsManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: echo\ntheme: default\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	m, err := output.ReadManifest(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != 1 {
+		t.Fatalf("manifest has %d entries, want 1", len(m.Entries))
+	}
+	for _, entry := range m.Entries {
+		if entry.Source != mdFile || entry.Title != "Hello" || entry.Theme != "default" || entry.MdpVersion != version {
+			t.Errorf("entry = %+v, want source, title, theme and version recorded", entry)
+		}
+	}
+}
+
+func TestRun_OutputPermissions(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: \"true\"\noutput_permissions: private\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	page := output.NewWriter(outputDir).BuildOutputPath(mdFile)
+	for path, want := range map[string]os.FileMode{
+		outputDir:          0700,
+		filepath.Dir(page): 0700,
+		page:               0600,
+		filepath.Join(outputDir, output.ManifestName): 0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("mode of %s = %o, want %o", path, got, want)
+		}
+	}
+}
+
+func TestRun_PrivateDocument(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "secret.md")
+	if err := os.WriteFile(mdFile, []byte("---\nprivate: true\n---\n# Secret"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	argsFile := filepath.Join(tmpDir, "args.txt")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf(`output_dir: %s
+browser_command:
+  - sh
+  - -c
+  - printf '%%s' "$1" > %s
+  - sh
+  - "{path}"
+`, outputDir, argsFile)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var page string
+	originalWait := waitForInterrupt
+	defer func() { waitForInterrupt = originalWait }()
+	waitForInterrupt = func() {
+		opened, err := os.ReadFile(argsFile) //nolint:gosec // G304: test file
+		if err != nil {
+			t.Fatal(err)
+		}
+		page = string(opened)
+		info, err := os.Stat(page)
+		if err != nil {
+			t.Fatalf("private page not found while mdp runs: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("mode of private page = %o, want 600", info.Mode().Perm())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	if page == "" || strings.HasPrefix(page, outputDir) {
+		t.Errorf("private page = %q, want a page outside the output directory", page)
+	}
+	if _, err := os.Stat(filepath.Dir(filepath.Dir(page))); !os.IsNotExist(err) {
+		t.Errorf("private output directory was not removed: %v", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("shared output directory was written: %v", err)
+	}
+}
+
+func TestReconvert_BecomesPrivate(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Draft"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr}
+	r, err := renderer.NewRenderer("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := output.NewWriter(filepath.Join(tmpDir, "output"))
+
+	shared, err := c.reconvert(mdFile, r, w)
+	if err != nil {
+		t.Fatalf("reconvert() returned error: %v", err)
+	}
+	if err := os.WriteFile(mdFile, []byte("---\nprivate: true\n---\n# Secret"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	if _, err := c.reconvert(mdFile, r, w); err == nil || !strings.Contains(err.Error(), "now private") {
+		t.Errorf("reconvert() error = %v, want error about the document becoming private", err)
+	}
+	if _, err := os.Stat(shared.Path); !os.IsNotExist(err) {
+		t.Errorf("shared preview of a private document was kept: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Removed shared preview: "+shared.Path) {
+		t.Errorf("stdout = %q, want the removed shared preview", stdout.String())
+	}
+}
+
+func TestRun_PrivateDocumentRemovesSharedPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "secret.md")
+	if err := os.WriteFile(mdFile, []byte("# Draft"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(tmpDir, "output")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: \"true\"\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	originalWait := waitForInterrupt
+	defer func() { waitForInterrupt = originalWait }()
+	waitForInterrupt = func() {}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+	shared := output.NewWriter(outputDir).BuildOutputPath(mdFile)
+	if _, err := os.Stat(shared); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(mdFile, []byte("---\nprivate: true\n---\n# Secret"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	c = &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+	if _, err := os.Stat(shared); !os.IsNotExist(err) {
+		t.Errorf("shared preview of a private document was kept: %v", err)
+	}
+	previews, err := output.Previews(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != 0 {
+		t.Errorf("Previews() = %v, want none", previews)
+	}
+}
+
+func TestRun_MarkdownConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("one\ntwo[^1] https://example.com\n\n[^1]: Note."), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: \"true\"\nmarkdown:\n  preset: github-comment\n  footnotes: true\n  linkify: false\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	html, err := os.ReadFile(output.NewWriter(outputDir).BuildOutputPath(mdFile)) //nolint:gosec // G304: test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"one<br>", `<sup id="fnref:1">`, " https://example.com"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("HTML = %q, want it to contain %q", html, want)
+		}
+	}
+	if strings.Contains(string(html), "<a href=\"https://example.com\"") {
+		t.Errorf("HTML = %q, want no autolink with linkify off", html)
+	}
+}
+
+func TestRun_EmojiConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte(":tada: :lgtm:"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: \"true\"\nemoji:\n  output: image\n  aliases:\n    lgtm: \":+1:\"\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+	if exitCode := c.run(mdFile, false); exitCode != 0 {
+		t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	html, err := os.ReadFile(output.NewWriter(outputDir).BuildOutputPath(mdFile)) //nolint:gosec // G304: test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`alt=":tada:" src="https://github.githubassets.com/images/icons/emoji/unicode/1f389.png"`, `alt=":lgtm:" src="https://github.githubassets.com/images/icons/emoji/unicode/1f44d.png"`} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("HTML = %q, want it to contain %q", html, want)
+		}
+	}
+}
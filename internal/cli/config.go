@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/masawada/mdp/internal/config"
+	"github.com/masawada/mdp/internal/renderer"
+)
+
+const configUsageMessage = `usage: mdp config <command> [options]
+
+Commands:
+  check  validate the config file and the environment it refers to
+  init   write a commented starter config file
+  path   list config file locations and mark the ones in use
+  show   print the effective config and where each value comes from
+
+Options:
+  --config <config-file>  path to config file
+  --no-project-config     ignore .mdp.yaml files in the current directory tree
+  --profile <name>        config profile to use`
+
+type configArgs struct {
+	command         string
+	configPath      string
+	noProjectConfig bool
+	profile         string
+}
+
+func parseConfigArgs(args []string) (*configArgs, error) {
+	if len(args) == 0 {
+		return nil, errors.New("config command is required")
+	}
+
+	fs := flag.NewFlagSet("mdp config", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", "", "path to config file")
+	noProjectConfig := fs.Bool("no-project-config", false, "ignore .mdp.yaml files in the current directory tree")
+	profile := fs.String("profile", "", "config profile to use")
+
+	command := args[0]
+	if command == "-h" || command == "--help" || command == "-help" {
+		return nil, errHelp
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+
+	switch command {
+	case "check", "init", "path", "show":
+		if fs.NArg() != 0 {
+			return nil, fmt.Errorf("config %s takes no arguments", command)
+		}
+	default:
+		return nil, fmt.Errorf("unknown config command: %s", command)
+	}
+
+	return &configArgs{
+		command:         command,
+		configPath:      *configPath,
+		noProjectConfig: *noProjectConfig,
+		profile:         *profile,
+	}, nil
+}
+
+func (c *cli) runConfig(args []string) int {
+	parsed, err := parseConfigArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, configUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, configUsageMessage)
+		return 1
+	}
+
+	c.configPath = parsed.configPath
+	c.noProjectConfig = parsed.noProjectConfig
+	c.profile = parsed.profile
+
+	switch parsed.command {
+	case "init":
+		return c.initConfig()
+	case "path":
+		return c.showConfigPath()
+	case "show":
+		return c.showConfig()
+	default:
+		return c.checkConfig()
+	}
+}
+
+func (c *cli) initConfig() int {
+	path, err := config.Init(c.configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintf(c.outWriter, "Created: %s\n", path)
+	return 0
+}
+
+func (c *cli) showConfigPath() int {
+	if c.configPath != "" {
+		_, _ = fmt.Fprintf(c.outWriter, "* %s (--config)\n", c.configPath)
+	} else {
+		used := false
+		seen := make(map[string]bool)
+		for _, path := range config.PathCandidates() {
+			// The candidates repeat when $XDG_CONFIG_HOME is ~/.config.
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			marker := " "
+			if _, err := os.Stat(path); err == nil && !used {
+				marker = "*"
+				used = true
+			}
+			_, _ = fmt.Fprintf(c.outWriter, "%s %s\n", marker, path)
+		}
+	}
+
+	switch {
+	case c.noProjectConfig || os.Getenv(config.NoProjectConfigEnv) != "":
+		_, _ = fmt.Fprintln(c.outWriter, "  (project config disabled)")
+	default:
+		if projectPath := config.FindProjectConfig("."); projectPath != "" {
+			_, _ = fmt.Fprintf(c.outWriter, "* %s (project)\n", projectPath)
+		}
+	}
+
+	return 0
+}
+
+func (c *cli) showConfig() int {
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(c.outWriter, 0, 0, 2, ' ', 0)
+	for _, entry := range cfg.Entries() {
+		_, _ = fmt.Fprintf(tw, "%s: %s\t# %s\n", entry.Key, entry.Value, entry.Source)
+	}
+	_ = tw.Flush()
+
+	if cfg.ActiveProfile != "" {
+		_, _ = fmt.Fprintf(c.outWriter, "# profile: %s\n", cfg.ActiveProfile)
+	}
+
+	return 0
+}
+
+func (c *cli) checkConfig() int {
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	if cfg.Path == "" {
+		_, _ = fmt.Fprintln(c.outWriter, "Config file: none (using defaults)")
+	} else {
+		_, _ = fmt.Fprintf(c.outWriter, "Config file: %s\n", cfg.Path)
+	}
+	if cfg.ProjectPath != "" {
+		_, _ = fmt.Fprintf(c.outWriter, "Project config file: %s\n", cfg.ProjectPath)
+	}
+	if cfg.ActiveProfile != "" {
+		_, _ = fmt.Fprintf(c.outWriter, "Profile: %s\n", cfg.ActiveProfile)
+	}
+
+	problems := config.Check(cfg)
+	if cfg.Theme != "" {
+		_, themeProblems, err := renderer.ValidateTheme(cfg.ConfigDir, cfg.Theme)
+		switch {
+		case err != nil:
+			problems = append(problems, config.Problem{Key: "theme", Message: err.Error()})
+		case len(themeProblems) > 0:
+			problems = append(problems, config.Problem{
+				Key:     "theme",
+				Message: fmt.Sprintf("%q has %d problem(s); run 'mdp theme validate %s' for details", cfg.Theme, len(themeProblems), cfg.Theme),
+			})
+		}
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			_, _ = fmt.Fprintf(c.errWriter, "error: %s\n", problem)
+		}
+		return 1
+	}
+
+	_, _ = fmt.Fprintln(c.outWriter, "Config OK")
+	return 0
+}
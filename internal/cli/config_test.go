@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   *configArgs
+		wantErrMsg string
+	}{
+		{
+			name:     "check",
+			args:     []string{"check"},
+			wantArgs: &configArgs{command: "check"},
+		},
+		{
+			name:     "check with config",
+			args:     []string{"check", "--config", "config.yaml"},
+			wantArgs: &configArgs{command: "check", configPath: "config.yaml"},
+		},
+		{
+			name:     "check without project config",
+			args:     []string{"check", "--no-project-config"},
+			wantArgs: &configArgs{command: "check", noProjectConfig: true},
+		},
+		{
+			name:       "no command",
+			args:       []string{},
+			wantErrMsg: "config command is required",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"edit"},
+			wantErrMsg: "unknown config command",
+		},
+		{
+			name:       "check with argument",
+			args:       []string{"check", "extra"},
+			wantErrMsg: "takes no arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfigArgs(tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("parseConfigArgs() error = %v, want error containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseConfigArgs() unexpected error = %v", err)
+			}
+			if *got != *tt.wantArgs {
+				t.Errorf("parseConfigArgs() = %+v, want %+v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Run("reports ok for valid config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := "output_dir: " + filepath.Join(tmpDir, "output") + "\nbrowser_command: echo\ntheme: default\n"
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noProjectConfig: true}
+
+		if exitCode := c.checkConfig(); exitCode != 0 {
+			t.Errorf("checkConfig() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "Config OK") {
+			t.Errorf("stdout = %q, want Config OK", stdout.String())
+		}
+	})
+
+	t.Run("reports unknown key", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(configFile, []byte("them: default\n"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noProjectConfig: true}
+
+		if exitCode := c.checkConfig(); exitCode != 1 {
+			t.Errorf("checkConfig() exit code = %d, want 1", exitCode)
+		}
+		if !strings.Contains(stderr.String(), `unknown key "them" (did you mean "theme"?)`) {
+			t.Errorf("stderr = %q", stderr.String())
+		}
+	})
+
+	t.Run("reports missing theme", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := "output_dir: " + filepath.Join(tmpDir, "output") + "\nbrowser_command: echo\ntheme: missing\n"
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noProjectConfig: true}
+
+		if exitCode := c.checkConfig(); exitCode != 1 {
+			t.Errorf("checkConfig() exit code = %d, want 1", exitCode)
+		}
+		if !strings.Contains(stderr.String(), "theme: theme not found") {
+			t.Errorf("stderr = %q", stderr.String())
+		}
+	})
+}
+
+func TestShowConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	content := "theme: default\nprofiles:\n  dark:\n    color_scheme: dark\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:       &stdout,
+		errWriter:       &stderr,
+		configPath:      configFile,
+		noProjectConfig: true,
+		profile:         "dark",
+		outputDir:       filepath.Join(tmpDir, "output"),
+	}
+
+	if exitCode := c.showConfig(); exitCode != 0 {
+		t.Fatalf("showConfig() exit code = %d\nstderr: %s", exitCode, stderr.String())
+	}
+
+	output := stdout.String()
+	wantPatterns := []string{
+		"theme: default",
+		"# " + configFile,
+		"color_scheme: dark",
+		"# profile dark (" + configFile + ")",
+		"output_dir: " + filepath.Join(tmpDir, "output"),
+		"# flag --output-dir",
+		"color_scheme_toggle: false",
+		"# default",
+		"# profile: dark",
+	}
+	for _, want := range wantPatterns {
+		if !strings.Contains(output, want) {
+			t.Errorf("showConfig() output should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestShowConfigPath(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:       &stdout,
+		errWriter:       &stderr,
+		configPath:      "/path/to/config.yaml",
+		noProjectConfig: true,
+	}
+
+	if exitCode := c.showConfigPath(); exitCode != 0 {
+		t.Fatalf("showConfigPath() exit code = %d", exitCode)
+	}
+	if want := "* /path/to/config.yaml (--config)\n  (project config disabled)\n"; stdout.String() != want {
+		t.Errorf("showConfigPath() output = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "mdp", "config.yaml")
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		outWriter:  &stdout,
+		errWriter:  &stderr,
+		configPath: configFile,
+	}
+
+	if exitCode := c.initConfig(); exitCode != 0 {
+		t.Fatalf("initConfig() exit code = %d\nstderr: %s", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Created: "+configFile) {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	if exitCode := c.initConfig(); exitCode != 1 {
+		t.Errorf("initConfig() exit code = %d, want 1 when file exists", exitCode)
+	}
+	if !strings.Contains(stderr.String(), "already exists") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
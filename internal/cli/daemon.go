@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/masawada/mdp/internal/browser"
+	"github.com/masawada/mdp/internal/config"
+	"github.com/masawada/mdp/internal/daemon"
+	"github.com/masawada/mdp/internal/output"
+	"github.com/masawada/mdp/internal/renderer"
+)
+
+const daemonUsageMessage = `usage: mdp daemon <command> [options]
+
+Commands:
+  start   start the preview daemon in the background
+  stop    stop the daemon
+  status  show whether the daemon is running and what it serves
+  run     run the daemon in the foreground
+
+Options:
+  --config <config-file>  path to config file
+  --profile <name>        config profile to use
+  --output-dir <dir>      output directory, overriding config
+  --browser <command>     browser command, overriding config`
+
+// daemonStartTimeout is how long "mdp daemon start" waits for the daemon
+// to accept requests.
+const daemonStartTimeout = 5 * time.Second
+
+type daemonArgs struct {
+	command        string
+	browserCommand string
+	configPath     string
+	outputDir      string
+	profile        string
+}
+
+func parseDaemonArgs(args []string) (*daemonArgs, error) {
+	if len(args) == 0 {
+		return nil, errors.New("daemon command is required")
+	}
+
+	fs := flag.NewFlagSet("mdp daemon", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	browserCommand := fs.String("browser", "", "browser command, overriding config")
+	configPath := fs.String("config", "", "path to config file")
+	outputDir := fs.String("output-dir", "", "output directory, overriding config")
+	profile := fs.String("profile", "", "config profile to use")
+
+	command := args[0]
+	if command == "-h" || command == "--help" || command == "-help" {
+		return nil, errHelp
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+
+	switch command {
+	case "run", "start", "status", "stop":
+		if fs.NArg() != 0 {
+			return nil, fmt.Errorf("daemon %s takes no arguments", command)
+		}
+	default:
+		return nil, fmt.Errorf("unknown daemon command: %s", command)
+	}
+
+	return &daemonArgs{
+		command:        command,
+		browserCommand: *browserCommand,
+		configPath:     *configPath,
+		outputDir:      *outputDir,
+		profile:        *profile,
+	}, nil
+}
+
+func (c *cli) runDaemon(args []string) int {
+	parsed, err := parseDaemonArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, daemonUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, daemonUsageMessage)
+		return 1
+	}
+
+	c.browserCommand = parsed.browserCommand
+	c.configPath = parsed.configPath
+	c.outputDir = parsed.outputDir
+	c.profile = parsed.profile
+	// The daemon serves every document in the output directory, so the
+	// project config of the current directory does not apply to it.
+	c.noProjectConfig = true
+
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	switch parsed.command {
+	case "start":
+		return c.startDaemon(cfg, parsed)
+	case "stop":
+		return c.stopDaemon(cfg)
+	case "status":
+		return c.daemonStatus(cfg)
+	default:
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		return c.serveDaemon(cfg, sigChan)
+	}
+}
+
+func (c *cli) startDaemon(cfg *config.Config, parsed *daemonArgs) int {
+	if status, err := daemon.NewClient(cfg.OutputDir).Status(); err == nil {
+		_, _ = fmt.Fprintf(c.outWriter, "Daemon already running (pid %d): %s\n", status.PID, status.URL)
+		return 0
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	// The daemon runs from another directory, so paths must be absolute.
+	command := []string{executable, "daemon", "run", "--output-dir", cfg.OutputDir}
+	if parsed.configPath != "" {
+		configPath, err := filepath.Abs(parsed.configPath)
+		if err != nil {
+			_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+			return 1
+		}
+		command = append(command, "--config", configPath)
+	}
+	if parsed.profile != "" {
+		command = append(command, "--profile", parsed.profile)
+	}
+	if parsed.browserCommand != "" {
+		command = append(command, "--browser", parsed.browserCommand)
+	}
+
+	status, err := daemon.Spawn(command, cfg.OutputDir, daemonStartTimeout)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to start daemon: %v\n", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintf(c.outWriter, "Daemon started (pid %d): %s\n", status.PID, status.URL)
+	return 0
+}
+
+func (c *cli) stopDaemon(cfg *config.Config) int {
+	err := daemon.NewClient(cfg.OutputDir).Stop()
+	if errors.Is(err, daemon.ErrNotRunning) {
+		_, _ = fmt.Fprintln(c.outWriter, "Daemon is not running")
+		return 0
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintln(c.outWriter, "Daemon stopped")
+	return 0
+}
+
+func (c *cli) daemonStatus(cfg *config.Config) int {
+	status, err := daemon.NewClient(cfg.OutputDir).Status()
+	if errors.Is(err, daemon.ErrNotRunning) {
+		_, _ = fmt.Fprintln(c.outWriter, "Daemon is not running")
+		return 1
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(c.outWriter, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Daemon:\trunning (pid %d)\n", status.PID)
+	_, _ = fmt.Fprintf(tw, "URL:\t%s\n", status.URL)
+	_, _ = fmt.Fprintf(tw, "Output dir:\t%s\n", status.OutputDir)
+	_, _ = fmt.Fprintf(tw, "Started:\t%s\n", status.StartedAt.Format(time.DateTime))
+	_ = tw.Flush()
+
+	if len(status.Pages) > 0 {
+		_, _ = fmt.Fprintln(c.outWriter, "Open pages:")
+		tw = tabwriter.NewWriter(c.outWriter, 0, 0, 2, ' ', 0)
+		for _, page := range status.Pages {
+			_, _ = fmt.Fprintf(tw, "  %s\t%d viewer(s)\n", page.URL, page.Viewers)
+		}
+		_ = tw.Flush()
+	}
+	if len(status.Watching) > 0 {
+		_, _ = fmt.Fprintln(c.outWriter, "Watching:")
+		for _, source := range status.Watching {
+			_, _ = fmt.Fprintf(c.outWriter, "  %s\n", source)
+		}
+	}
+
+	return 0
+}
+
+// serveDaemon runs the daemon until it is stopped or a signal arrives.
+func (c *cli) serveDaemon(cfg *config.Config, sigChan <-chan os.Signal) int {
+	opts := []daemon.Option{daemon.WithLogger(c.errWriter)}
+	if len(cfg.BrowserCommand) > 0 {
+		opener := browser.NewOpener(cfg.BrowserCommand, browser.WithTimeout(time.Duration(cfg.BrowserTimeout)))
+		opts = append(opts, daemon.WithOpener(opener.Open))
+	}
+
+	server, err := daemon.NewServer(cfg.OutputDir, c.daemonRender, opts...)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+	if err := server.Start(); err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to start daemon: %v\n", err)
+		return 1
+	}
+	defer server.Stop()
+
+	_, _ = fmt.Fprintf(c.outWriter, "Serving %s (pid %d)\n", server.URL(), os.Getpid())
+
+	select {
+	case <-server.Done():
+	case <-sigChan:
+	}
+	return 0
+}
+
+// daemonOptions carries the command-line settings a document is rendered
+// with from the invocation that hands it to the daemon.
+type daemonOptions struct {
+	ConfigPath      string `json:"config_path,omitempty"`
+	NoProjectConfig bool   `json:"no_project_config,omitempty"`
+	OutputDir       string `json:"output_dir,omitempty"`
+	Profile         string `json:"profile,omitempty"`
+	ThemeName       string `json:"theme,omitempty"`
+	PrintMode       bool   `json:"print,omitempty"`
+	// Env holds the invocation's MDP_* environment variables, which are
+	// used instead of the daemon's own.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// daemonRender generates a document in the daemon with the settings of the
+// invocation that requested it.
+func (c *cli) daemonRender(source string, raw json.RawMessage) (daemon.Page, error) {
+	var opts daemonOptions
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &opts); err != nil {
+			return daemon.Page{}, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	rc := &cli{
+		outWriter:       io.Discard,
+		errWriter:       io.Discard,
+		configPath:      opts.ConfigPath,
+		noProjectConfig: opts.NoProjectConfig,
+		outputDir:       opts.OutputDir,
+		profile:         opts.Profile,
+		themeName:       opts.ThemeName,
+		printMode:       opts.PrintMode,
+		env:             opts.Env,
+	}
+	if rc.env == nil {
+		rc.env = map[string]string{}
+	}
+	cfg, err := rc.loadConfig(filepath.Dir(source))
+	if err != nil {
+		return daemon.Page{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	r, err := renderer.NewRenderer(cfg.ConfigDir, cfg.Theme, rc.rendererOptions(cfg)...)
+	if err != nil {
+		return daemon.Page{}, fmt.Errorf("failed to initialize renderer: %w", err)
+	}
+
+	markdown, err := os.ReadFile(source) //nolint:gosec // G304: path is user-specified input file
+	if err != nil {
+		return daemon.Page{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	doc, err := r.RenderDocument(markdown)
+	if err != nil {
+		return daemon.Page{}, fmt.Errorf("failed to render: %w", err)
+	}
+	if doc.Private {
+		// A document that became private while watched must not keep its
+		// shared page.
+		if _, err := newWriter(cfg).RemoveSource(source); err != nil {
+			return daemon.Page{}, fmt.Errorf("the document is private, but its shared preview could not be removed: %w", err)
+		}
+		return daemon.Page{}, errors.New("the document is private and is not rendered by the daemon; preview it with mdp instead")
+	}
+	result, err := newWriter(cfg).WriteDocument(source, doc.HTML, documentMetadata(doc, markdown))
+	if err != nil {
+		return daemon.Page{}, fmt.Errorf("failed to write html: %w", err)
+	}
+
+	return daemon.Page{Path: result.Path, Title: doc.Title, Unchanged: result.Status == output.Unchanged}, nil
+}
+
+// handOff passes the document to a running daemon for cfg's output
+// directory. It reports false if no daemon is running.
+func (c *cli) handOff(absPath string, cfg *config.Config, watchMode bool) (int, bool) {
+	if _, err := os.Stat(daemon.SocketPath(cfg.OutputDir)); err != nil {
+		return 0, false
+	}
+
+	opts := daemonOptions{
+		NoProjectConfig: c.noProjectConfig,
+		OutputDir:       cfg.OutputDir,
+		Profile:         c.profile,
+		ThemeName:       c.themeName,
+		PrintMode:       c.printMode,
+		Env:             config.Environ(),
+	}
+	if c.configPath != "" {
+		configPath, err := filepath.Abs(c.configPath)
+		if err != nil {
+			_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+			return 1, true
+		}
+		opts.ConfigPath = configPath
+	}
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1, true
+	}
+
+	resp, err := daemon.NewClient(cfg.OutputDir).Open(daemon.Request{Source: absPath, Watch: watchMode, Options: raw})
+	if errors.Is(err, daemon.ErrNotRunning) {
+		return 0, false
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: daemon: %v\n", err)
+		return 1, true
+	}
+
+	_, _ = fmt.Fprintf(c.outWriter, "Generated: %s\n", resp.Path)
+	switch resp.Action {
+	case daemon.ActionReloaded:
+		_, _ = fmt.Fprintf(c.outWriter, "Reloaded: %s\n", resp.URL)
+	case daemon.ActionOpened:
+		_, _ = fmt.Fprintf(c.outWriter, "Opened: %s\n", resp.URL)
+	default:
+		_, _ = fmt.Fprintf(c.outWriter, "No browser available; open %s\n", resp.URL)
+	}
+	if watchMode {
+		_, _ = fmt.Fprintln(c.outWriter, "Watching for changes in the daemon (stop with 'mdp daemon stop')")
+	}
+	return 0, true
+}
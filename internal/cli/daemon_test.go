@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/masawada/mdp/internal/daemon"
+)
+
+func TestParseDaemonArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   *daemonArgs
+		wantErrMsg string
+	}{
+		{
+			name:     "start",
+			args:     []string{"start"},
+			wantArgs: &daemonArgs{command: "start"},
+		},
+		{
+			name:     "start with options",
+			args:     []string{"start", "--config", "config.yaml", "--output-dir", "/tmp/out", "--browser", "firefox {url}"},
+			wantArgs: &daemonArgs{command: "start", configPath: "config.yaml", outputDir: "/tmp/out", browserCommand: "firefox {url}"},
+		},
+		{
+			name:     "status with profile",
+			args:     []string{"status", "--profile", "review"},
+			wantArgs: &daemonArgs{command: "status", profile: "review"},
+		},
+		{
+			name:       "no command",
+			args:       []string{},
+			wantErrMsg: "daemon command is required",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"restart"},
+			wantErrMsg: "unknown daemon command",
+		},
+		{
+			name:       "stop with argument",
+			args:       []string{"stop", "extra"},
+			wantErrMsg: "takes no arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDaemonArgs(tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("parseDaemonArgs() error = %v, want error containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDaemonArgs() unexpected error = %v", err)
+			}
+			if *got != *tt.wantArgs {
+				t.Errorf("parseDaemonArgs() = %+v, want %+v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDaemon(t *testing.T) {
+	// Unix socket paths are limited in length, so avoid t.TempDir.
+	outputDir, err := os.MkdirTemp("", "mdpd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(outputDir) })
+
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Hello"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\nbrowser_command: \"true\"\n", outputDir)
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	var daemonOut bytes.Buffer
+	server := &cli{outWriter: &daemonOut, errWriter: &daemonOut, configPath: configFile, noProjectConfig: true}
+	cfg, err := server.loadConfig(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigChan := make(chan os.Signal, 1)
+	exited := make(chan int, 1)
+	go func() {
+		exited <- server.serveDaemon(cfg, sigChan)
+	}()
+	t.Cleanup(func() {
+		sigChan <- syscall.SIGTERM
+		<-exited
+	})
+
+	client := daemon.NewClient(outputDir)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := client.Status(); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not start: %s", daemonOut.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Run("run hands the file to the daemon", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile}
+
+		exitCode := c.run(mdFile, true)
+		if exitCode != 0 {
+			t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "Opened: http://127.0.0.1:") {
+			t.Errorf("stdout = %q, want page opened by the daemon", stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "Watching for changes in the daemon") {
+			t.Errorf("stdout = %q, want watch handed to the daemon", stdout.String())
+		}
+	})
+
+	t.Run("documents are rendered with the caller's MDP_ variables", func(t *testing.T) {
+		t.Setenv("MDP_COLOR_SCHEME", "light")
+		raw, err := json.Marshal(daemonOptions{
+			ConfigPath:      configFile,
+			NoProjectConfig: true,
+			Env:             map[string]string{"MDP_THEME": "default", "MDP_COLOR_SCHEME": "dark"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		page, err := server.daemonRender(mdFile, raw)
+		if err != nil {
+			t.Fatalf("daemonRender() returned error: %v", err)
+		}
+		html, err := os.ReadFile(page.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(html), `data-color-scheme="dark"`) {
+			t.Errorf("page = %q, want the caller's color scheme", html)
+		}
+	})
+
+	t.Run("status lists watched files", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+
+		if exitCode := c.daemonStatus(cfg); exitCode != 0 {
+			t.Fatalf("daemonStatus() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		for _, want := range []string{"running (pid", "URL:", mdFile} {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), want)
+			}
+		}
+	})
+
+	t.Run("no-daemon renders locally", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile, noDaemon: true}
+
+		if exitCode := c.run(mdFile, false); exitCode != 0 {
+			t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		if strings.Contains(stdout.String(), "http://") {
+			t.Errorf("stdout = %q, want local rendering", stdout.String())
+		}
+	})
+
+	t.Run("private documents are rendered locally", func(t *testing.T) {
+		secretFile := filepath.Join(tmpDir, "secret.md")
+		if err := os.WriteFile(secretFile, []byte("---\nprivate: true\n---\n# Secret"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		originalWait := waitForInterrupt
+		defer func() { waitForInterrupt = originalWait }()
+		waitForInterrupt = func() {}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, configPath: configFile}
+		if exitCode := c.run(secretFile, false); exitCode != 0 {
+			t.Fatalf("run() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		if strings.Contains(stdout.String(), "http://") || strings.Contains(stdout.String(), outputDir) {
+			t.Errorf("stdout = %q, want a local private preview", stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "Private document") {
+			t.Errorf("stdout = %q, want private document notice", stdout.String())
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+
+		if exitCode := c.stopDaemon(cfg); exitCode != 0 {
+			t.Fatalf("stopDaemon() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		select {
+		case code := <-exited:
+			exited <- code
+		case <-time.After(5 * time.Second):
+			t.Fatal("daemon did not exit")
+		}
+
+		stdout.Reset()
+		if exitCode := c.daemonStatus(cfg); exitCode != 1 {
+			t.Errorf("daemonStatus() exit code = %d, want 1", exitCode)
+		}
+		if !strings.Contains(stdout.String(), "not running") {
+			t.Errorf("stdout = %q, want not running", stdout.String())
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+// List formats accepted by --format.
+const (
+	listFormatTable = "table"
+	listFormatJSON  = "json"
+	listFormatTSV   = "tsv"
+)
+
+// List orders accepted by --sort.
+const (
+	listSortTime   = "time"
+	listSortSource = "source"
+	listSortTitle  = "title"
+	listSortOutput = "output"
+)
+
+// listOptions controls how --list prints previews.
+type listOptions struct {
+	// format is one of the listFormat constants; empty means table.
+	format string
+	// sort is one of the listSort constants; empty means time.
+	sort string
+	// under limits the list to sources under this directory.
+	under string
+}
+
+func (o listOptions) validate() error {
+	switch o.format {
+	case "", listFormatTable, listFormatJSON, listFormatTSV:
+	default:
+		return fmt.Errorf("invalid format %q: must be one of table, json, tsv", o.format)
+	}
+	switch o.sort {
+	case "", listSortTime, listSortSource, listSortTitle, listSortOutput:
+	default:
+		return fmt.Errorf("invalid sort %q: must be one of time, source, title, output", o.sort)
+	}
+	return nil
+}
+
+// filter returns the previews whose source is under o.under.
+func (o listOptions) filter(previews []output.Preview) ([]output.Preview, error) {
+	if o.under == "" {
+		return previews, nil
+	}
+	dir, err := filepath.Abs(o.under)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(previews, func(p output.Preview) bool {
+		return !strings.HasPrefix(p.Source, dir+string(filepath.Separator))
+	}), nil
+}
+
+// sortPreviews orders previews in place. Time sorts newest first, the
+// other orders are ascending.
+func (o listOptions) sortPreviews(previews []output.Preview) {
+	slices.SortStableFunc(previews, func(a, b output.Preview) int {
+		switch o.sort {
+		case listSortSource:
+			return cmp.Compare(a.Source, b.Source)
+		case listSortTitle:
+			return cmp.Or(cmp.Compare(a.Title, b.Title), cmp.Compare(a.Source, b.Source))
+		case listSortOutput:
+			return cmp.Compare(a.Path, b.Path)
+		default:
+			return b.GeneratedAt.Compare(a.GeneratedAt)
+		}
+	})
+}
+
+// listEntry is a preview as printed by --list --format json.
+type listEntry struct {
+	Output      string    `json:"output"`
+	Source      string    `json:"source"`
+	Title       string    `json:"title"`
+	Theme       string    `json:"theme,omitempty"`
+	Status      string    `json:"status"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+func (o listOptions) write(w io.Writer, previews []output.Preview) error {
+	switch o.format {
+	case listFormatJSON:
+		entries := make([]listEntry, 0, len(previews))
+		for _, p := range previews {
+			entries = append(entries, listEntry{
+				Output:      p.Path,
+				Source:      p.Source,
+				Title:       p.Title,
+				Theme:       p.Theme,
+				Status:      string(p.Status),
+				GeneratedAt: p.GeneratedAt,
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case listFormatTSV:
+		for _, p := range previews {
+			fields := []string{p.Path, p.Source, p.Title, string(p.Status), p.GeneratedAt.Format(time.RFC3339)}
+			for i, field := range fields {
+				// Keep one record per line for cut and fzf.
+				fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(field)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if len(previews) == 0 {
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "GENERATED\tSTATUS\tTITLE\tSOURCE\tOUTPUT")
+		for _, p := range previews {
+			title := p.Title
+			if title == "" {
+				title = "-"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.GeneratedAt.Local().Format("2006-01-02 15:04"), p.Status, title, p.Source, p.Path)
+		}
+		return tw.Flush()
+	}
+}
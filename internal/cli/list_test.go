@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+func TestListFiles_Formats(t *testing.T) {
+	outputDir := t.TempDir()
+	srcDir := t.TempDir()
+	w := output.NewWriter(outputDir)
+
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	docs := []struct {
+		name, title string
+		age         time.Duration
+	}{
+		{"notes/b", "Beta", time.Hour},
+		{"a", "Alpha", 2 * time.Hour},
+		{"notes/c", "Gamma", 0},
+	}
+	paths := make(map[string]string)
+	for _, doc := range docs {
+		src := filepath.Join(srcDir, doc.name+".md")
+		if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		markdown := []byte("# " + doc.title)
+		if err := os.WriteFile(src, markdown, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		result, err := w.WriteDocument(src, []byte("x"), output.Metadata{Title: doc.title, Markdown: markdown})
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := output.Key(outputDir, result.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = output.UpdateManifest(outputDir, func(m *output.Manifest) error {
+			m.Entries[key].RenderedAt = base.Add(-doc.age)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[doc.name] = result.Path
+	}
+	if err := os.Remove(filepath.Join(srcDir, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(t *testing.T, opts listOptions) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr, outputDir: outputDir}
+		if exitCode := c.listFiles(opts); exitCode != 0 {
+			t.Fatalf("listFiles() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	t.Run("table sorted by time", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(list(t, listOptions{})), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("got %d lines, want header and 3 rows:\n%s", len(lines), strings.Join(lines, "\n"))
+		}
+		if !strings.HasPrefix(lines[0], "GENERATED") {
+			t.Errorf("header = %q", lines[0])
+		}
+		for i, title := range []string{"Gamma", "Beta", "Alpha"} {
+			if !strings.Contains(lines[i+1], title) {
+				t.Errorf("row %d = %q, want %s", i+1, lines[i+1], title)
+			}
+		}
+		if !strings.Contains(lines[3], "deleted") {
+			t.Errorf("row for removed source = %q, want deleted status", lines[3])
+		}
+	})
+
+	t.Run("tsv sorted by title", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(list(t, listOptions{format: listFormatTSV, sort: listSortTitle})), "\n")
+		want := []string{
+			strings.Join([]string{paths["a"], filepath.Join(srcDir, "a.md"), "Alpha", "deleted", "2026-05-01T10:00:00Z"}, "\t"),
+			strings.Join([]string{paths["notes/b"], filepath.Join(srcDir, "notes/b.md"), "Beta", "current", "2026-05-01T11:00:00Z"}, "\t"),
+			strings.Join([]string{paths["notes/c"], filepath.Join(srcDir, "notes/c.md"), "Gamma", "current", "2026-05-01T12:00:00Z"}, "\t"),
+		}
+		if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+			t.Errorf("tsv =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+		}
+	})
+
+	t.Run("json under directory", func(t *testing.T) {
+		var entries []listEntry
+		out := list(t, listOptions{format: listFormatJSON, sort: listSortSource, under: filepath.Join(srcDir, "notes")})
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("invalid json: %v\n%s", err, out)
+		}
+		if len(entries) != 2 || entries[0].Title != "Beta" || entries[1].Title != "Gamma" {
+			t.Fatalf("entries = %+v, want Beta and Gamma", entries)
+		}
+		if entries[0].Output != paths["notes/b"] || entries[0].Status != "current" {
+			t.Errorf("entry = %+v", entries[0])
+		}
+	})
+
+	t.Run("json with no previews", func(t *testing.T) {
+		out := list(t, listOptions{format: listFormatJSON, under: filepath.Join(srcDir, "missing")})
+		if strings.TrimSpace(out) != "[]" {
+			t.Errorf("json = %q, want []", out)
+		}
+	})
+}
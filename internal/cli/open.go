@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/masawada/mdp/internal/browser"
+	"github.com/masawada/mdp/internal/output"
+)
+
+const openUsageMessage = `usage: mdp open [options] <query>
+
+Opens the previously generated preview whose source path or title best
+matches <query>. The preview is regenerated first if its source has changed.
+
+Options:
+  --config <config-file>  path to config file
+  --profile <name>        config profile to use
+  --output-dir <dir>      output directory, overriding config
+  --browser <command>     browser command, overriding config`
+
+type openArgs struct {
+	browserCommand string
+	configPath     string
+	outputDir      string
+	profile        string
+	query          string
+}
+
+func parseOpenArgs(args []string) (*openArgs, error) {
+	fs := flag.NewFlagSet("mdp open", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	browserCommand := fs.String("browser", "", "browser command, overriding config")
+	configPath := fs.String("config", "", "path to config file")
+	outputDir := fs.String("output-dir", "", "output directory, overriding config")
+	profile := fs.String("profile", "", "config profile to use")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+
+	return &openArgs{
+		browserCommand: *browserCommand,
+		configPath:     *configPath,
+		outputDir:      *outputDir,
+		profile:        *profile,
+		query:          query,
+	}, nil
+}
+
+// bestMatch returns the preview that best matches query, preferring the
+// most recent one on a tie. previews must be sorted newest first.
+func bestMatch(previews []output.Preview, query string) (output.Preview, bool) {
+	var best output.Preview
+	bestScore := 0
+	for _, p := range previews {
+		score, ok := matchScore(p, query)
+		if ok && score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// matchScore scores p against every word of query. Each word must match
+// the source path or the title.
+func matchScore(p output.Preview, query string) (int, bool) {
+	total := 0
+	for _, word := range strings.Fields(query) {
+		sourceScore, sourceOK := fuzzyScore(word, p.Source)
+		titleScore, titleOK := fuzzyScore(word, p.Title)
+		if !sourceOK && !titleOK {
+			return 0, false
+		}
+		total += max(sourceScore, titleScore)
+	}
+	return total, true
+}
+
+// fuzzyScore reports whether the characters of query appear in text in
+// order, ignoring case, and how well they match. Consecutive characters and
+// characters at the start of a word score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, false
+	}
+
+	score, i := 0, 0
+	prevMatched := false
+	var prev rune
+	for _, r := range strings.ToLower(text) {
+		if i < len(q) && r == q[i] {
+			score++
+			if prevMatched {
+				score += 5
+			}
+			if prev == 0 || !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 3
+			}
+			i++
+			prevMatched = true
+		} else {
+			prevMatched = false
+		}
+		prev = r
+	}
+	return score, i == len(q)
+}
+
+func (c *cli) runOpen(args []string) int {
+	parsed, err := parseOpenArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, openUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, openUsageMessage)
+		return 1
+	}
+
+	c.browserCommand = parsed.browserCommand
+	c.configPath = parsed.configPath
+	c.outputDir = parsed.outputDir
+	c.profile = parsed.profile
+
+	previews, err := c.recentPreviews()
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+	preview, ok := bestMatch(previews, parsed.query)
+	if !ok {
+		_, _ = fmt.Fprintf(c.errWriter, "error: no preview matches %q\n", parsed.query)
+		return 1
+	}
+
+	if preview.Status == output.StatusStale {
+		// Regenerate from the source with the theme and print mode the page
+		// was generated with, which also opens the result.
+		c.themeName = preview.Theme
+		c.printMode = preview.Print
+		return c.run(preview.Source, false)
+	}
+
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
+		return 1
+	}
+	if len(cfg.BrowserCommand) == 0 {
+		_, _ = fmt.Fprintf(c.outWriter, "No browser available; open %s manually or set browser_command\n", preview.Path)
+		return 0
+	}
+	opener := browser.NewOpener(cfg.BrowserCommand, browser.WithTimeout(time.Duration(cfg.BrowserTimeout)))
+	if err := opener.Open(browser.Target{Path: preview.Path, Title: preview.Title}); err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to open browser: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(c.outWriter, "Opened: %s\n", preview.Path)
+	return 0
+}
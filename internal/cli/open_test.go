@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		wantOK      bool
+	}{
+		{"readme", "/src/mdp/README.md", true},
+		{"rdm", "/src/mdp/README.md", true},
+		{"mdr", "/src/mdp/README.md", true},
+		{"xyz", "/src/mdp/README.md", false},
+		{"emdaer", "/src/mdp/README.md", false},
+		{"a", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+" in "+tt.text, func(t *testing.T) {
+			if _, ok := fuzzyScore(tt.query, tt.text); ok != tt.wantOK {
+				t.Errorf("fuzzyScore(%q, %q) ok = %v, want %v", tt.query, tt.text, ok, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("consecutive and word start score higher", func(t *testing.T) {
+		consecutive, _ := fuzzyScore("note", "/docs/notes.md")
+		scattered, _ := fuzzyScore("note", "/nothing/else.md")
+		if consecutive <= scattered {
+			t.Errorf("consecutive score %d <= scattered score %d", consecutive, scattered)
+		}
+	})
+}
+
+func TestBestMatch(t *testing.T) {
+	// Newest first, as returned by recentPreviews.
+	previews := []output.Preview{
+		{Path: "/out/3", Source: "/work/api/README.md", Title: "API"},
+		{Path: "/out/2", Source: "/work/notes/meeting.md", Title: "Weekly Meeting"},
+		{Path: "/out/1", Source: "/work/mdp/README.md", Title: "mdp"},
+	}
+
+	tests := []struct {
+		query    string
+		wantPath string
+	}{
+		{"meeting", "/out/2"},
+		{"weekly", "/out/2"},
+		{"readme", "/out/3"},
+		{"mdp readme", "/out/1"},
+		{"nothing here", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := bestMatch(previews, tt.query)
+			if tt.wantPath == "" {
+				if ok {
+					t.Errorf("bestMatch() = %s, want no match", got.Path)
+				}
+				return
+			}
+			if !ok || got.Path != tt.wantPath {
+				t.Errorf("bestMatch() = %s, %v, want %s", got.Path, ok, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestRunOpen(t *testing.T) {
+	setup := func(t *testing.T) (configFile, argsFile, srcDir string, paths map[string]string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		outputDir := filepath.Join(tmpDir, "output")
+		srcDir = filepath.Join(tmpDir, "src")
+		paths = writePreviews(t, outputDir, srcDir, [][2]string{
+			{"notes/meeting", "Weekly Meeting"},
+			{"README", "Project"},
+		})
+
+		argsFile = filepath.Join(tmpDir, "args.txt")
+		configFile = filepath.Join(tmpDir, "config.yaml")
+		configContent := fmt.Sprintf(`output_dir: %s
+browser_command:
+  - sh
+  - -c
+  - printf '%%s' "$1" > %s
+  - sh
+  - "{path}"
+`, outputDir, argsFile)
+		if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		return configFile, argsFile, srcDir, paths
+	}
+
+	t.Run("opens the best match", func(t *testing.T) {
+		configFile, argsFile, _, paths := setup(t)
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+		if exitCode := c.runOpen([]string{"--config", configFile, "meet"}); exitCode != 0 {
+			t.Fatalf("runOpen() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		got, err := os.ReadFile(argsFile) //nolint:gosec // G304: test file
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != paths["notes/meeting"] {
+			t.Errorf("opened %q, want %q", got, paths["notes/meeting"])
+		}
+		if !strings.Contains(stdout.String(), "Opened: "+paths["notes/meeting"]) {
+			t.Errorf("stdout = %q, want Opened line", stdout.String())
+		}
+	})
+
+	t.Run("regenerates a stale preview", func(t *testing.T) {
+		configFile, argsFile, srcDir, paths := setup(t)
+		if err := os.WriteFile(filepath.Join(srcDir, "README.md"), []byte("# Renamed"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+		if exitCode := c.runOpen([]string{"--config", configFile, "readme"}); exitCode != 0 {
+			t.Fatalf("runOpen() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		html, err := os.ReadFile(paths["README"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(html), "Renamed") {
+			t.Errorf("preview was not regenerated:\n%s", html)
+		}
+		if _, err := os.Stat(argsFile); err != nil {
+			t.Errorf("browser was not opened: %v", err)
+		}
+	})
+
+	t.Run("stale preview keeps its theme for printing", func(t *testing.T) {
+		configFile, _, srcDir, paths := setup(t)
+		outputDir := filepath.Join(filepath.Dir(srcDir), "output")
+		key, err := output.Key(outputDir, paths["README"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = output.UpdateManifest(outputDir, func(m *output.Manifest) error {
+			m.Entries[key].Theme = "default"
+			m.Entries[key].Print = true
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(srcDir, "README.md"), []byte("# Renamed"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+		if exitCode := c.runOpen([]string{"--config", configFile, "readme"}); exitCode != 0 {
+			t.Fatalf("runOpen() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+		}
+		html, err := os.ReadFile(paths["README"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(html), "<title>Renamed</title>") || !strings.Contains(string(html), "@page") {
+			t.Errorf("preview was not regenerated with the default theme for printing:\n%s", html)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		configFile, _, _, _ := setup(t)
+
+		var stdout, stderr bytes.Buffer
+		c := &cli{outWriter: &stdout, errWriter: &stderr}
+		if exitCode := c.runOpen([]string{"--config", configFile, "zzz"}); exitCode != 1 {
+			t.Fatalf("runOpen() exit code = %d, want 1", exitCode)
+		}
+		if !strings.Contains(stderr.String(), `no preview matches "zzz"`) {
+			t.Errorf("stderr = %q", stderr.String())
+		}
+	})
+}
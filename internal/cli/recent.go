@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+const recentUsageMessage = `usage: mdp recent [options]
+
+Lists the most recently generated previews.
+
+Options:
+  -n <count>              number of previews to list (default 10)
+  --config <config-file>  path to config file
+  --profile <name>        config profile to use
+  --output-dir <dir>      output directory, overriding config`
+
+const defaultRecentCount = 10
+
+type recentArgs struct {
+	configPath string
+	count      int
+	outputDir  string
+	profile    string
+}
+
+func parseRecentArgs(args []string) (*recentArgs, error) {
+	fs := flag.NewFlagSet("mdp recent", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", "", "path to config file")
+	count := fs.Int("n", defaultRecentCount, "number of previews to list")
+	outputDir := fs.String("output-dir", "", "output directory, overriding config")
+	profile := fs.String("profile", "", "config profile to use")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+	if fs.NArg() != 0 {
+		return nil, errors.New("recent takes no arguments")
+	}
+	if *count <= 0 {
+		return nil, fmt.Errorf("invalid count %d: must be positive", *count)
+	}
+
+	return &recentArgs{
+		configPath: *configPath,
+		count:      *count,
+		outputDir:  *outputDir,
+		profile:    *profile,
+	}, nil
+}
+
+// recentPreviews returns the previews in the configured output directory,
+// newest first.
+func (c *cli) recentPreviews() ([]output.Preview, error) {
+	cfg, err := c.loadConfig(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	previews, err := output.Previews(cfg.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	listOptions{sort: listSortTime}.sortPreviews(previews)
+	return previews, nil
+}
+
+func (c *cli) runRecent(args []string) int {
+	parsed, err := parseRecentArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, recentUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, recentUsageMessage)
+		return 1
+	}
+
+	c.configPath = parsed.configPath
+	c.outputDir = parsed.outputDir
+	c.profile = parsed.profile
+
+	previews, err := c.recentPreviews()
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+	if len(previews) > parsed.count {
+		previews = previews[:parsed.count]
+	}
+
+	tw := tabwriter.NewWriter(c.outWriter, 0, 0, 2, ' ', 0)
+	for _, p := range previews {
+		title := p.Title
+		if title == "" {
+			title = "-"
+		}
+		source := p.Source
+		if p.Status != output.StatusCurrent {
+			source += " (" + string(p.Status) + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", p.GeneratedAt.Local().Format("2006-01-02 15:04"), title, source)
+	}
+	if err := tw.Flush(); err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
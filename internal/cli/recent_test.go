@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masawada/mdp/internal/output"
+)
+
+// writePreviews generates a preview for each of the named documents, with
+// the given titles, generated one hour apart in order.
+func writePreviews(t *testing.T, outputDir, srcDir string, docs [][2]string) map[string]string {
+	t.Helper()
+	w := output.NewWriter(outputDir)
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	paths := make(map[string]string)
+	for i, doc := range docs {
+		name, title := doc[0], doc[1]
+		src := filepath.Join(srcDir, name+".md")
+		if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		markdown := []byte("# " + title)
+		if err := os.WriteFile(src, markdown, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		result, err := w.WriteDocument(src, []byte("<h1>"+title+"</h1>"), output.Metadata{Title: title, Markdown: markdown})
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := output.Key(outputDir, result.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = output.UpdateManifest(outputDir, func(m *output.Manifest) error {
+			m.Entries[key].RenderedAt = base.Add(time.Duration(i) * time.Hour)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = result.Path
+	}
+	return paths
+}
+
+func TestParseRecentArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCount  int
+		wantErrMsg string
+	}{
+		{name: "default count", args: nil, wantCount: defaultRecentCount},
+		{name: "count", args: []string{"-n", "3"}, wantCount: 3},
+		{name: "zero count", args: []string{"-n", "0"}, wantErrMsg: "invalid count 0"},
+		{name: "arguments", args: []string{"foo"}, wantErrMsg: "recent takes no arguments"},
+		{name: "help", args: []string{"--help"}, wantErrMsg: "help requested"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecentArgs(tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Fatalf("parseRecentArgs() error = %v, want error containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRecentArgs() unexpected error = %v", err)
+			}
+			if got.count != tt.wantCount {
+				t.Errorf("parseRecentArgs() count = %d, want %d", got.count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestRunRecent(t *testing.T) {
+	outputDir := t.TempDir()
+	srcDir := t.TempDir()
+	writePreviews(t, outputDir, srcDir, [][2]string{
+		{"old", "Old Notes"},
+		{"gone", "Gone"},
+		{"new", "New Notes"},
+	})
+	if err := os.Remove(filepath.Join(srcDir, "gone.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{outWriter: &stdout, errWriter: &stderr}
+	if exitCode := c.runRecent([]string{"-n", "2", "--output-dir", outputDir}); exitCode != 0 {
+		t.Fatalf("runRecent() exit code = %d, want 0\nstderr: %s", exitCode, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), stdout.String())
+	}
+	if !strings.Contains(lines[0], "New Notes") || !strings.HasSuffix(lines[0], filepath.Join(srcDir, "new.md")) {
+		t.Errorf("line 1 = %q, want the newest preview", lines[0])
+	}
+	if !strings.Contains(lines[1], "Gone") || !strings.HasSuffix(lines[1], "(deleted)") {
+		t.Errorf("line 2 = %q, want the deleted preview", lines[1])
+	}
+}
@@ -7,16 +7,53 @@ import (
 )
 
 const usageMessage = `usage: mdp [options] <markdown-file>
+       mdp clean <selector>... [options]
+       mdp config <command> [options]
+       mdp daemon <command> [options]
+       mdp open [options] <query>
+       mdp recent [options]
+       mdp theme <command> [options]
 
 Options:
   --config <config-file>  path to config file
+  --profile <name>        config profile to use
+  --output-dir <dir>      output directory, overriding config
+  --browser <command>     browser command, overriding config
+  --no-project-config     ignore .mdp.yaml files in the document's directory tree
+  --no-daemon             render locally even if a daemon is running
+  --theme <name>          theme to use, overriding front-matter and config
+  --print                 render for printing with a paged-media stylesheet
   --watch                 watch for file changes and regenerate
   --list                  list generated files
+  --format <format>       list format: table, json or tsv (with --list)
+  --sort <order>          list order: time, source, title or output (with --list)
+  --under <dir>           list only previews of files under <dir> (with --list)
   --version               show version
   --help                  show this help message`
 
 // Run executes the mdp command and returns the exit code.
 func Run() int {
+	if len(os.Args) > 1 {
+		c := &cli{
+			outWriter: os.Stdout,
+			errWriter: os.Stderr,
+		}
+		switch os.Args[1] {
+		case "clean":
+			return c.runClean(os.Args[2:])
+		case "config":
+			return c.runConfig(os.Args[2:])
+		case "daemon":
+			return c.runDaemon(os.Args[2:])
+		case "open":
+			return c.runOpen(os.Args[2:])
+		case "recent":
+			return c.runRecent(os.Args[2:])
+		case "theme":
+			return c.runTheme(os.Args[2:])
+		}
+	}
+
 	args, err := parseArgs(os.Args[1:])
 	if err != nil {
 		if errors.Is(err, errHelp) {
@@ -34,13 +71,20 @@ func Run() int {
 	}
 
 	c := &cli{
-		outWriter:  os.Stdout,
-		errWriter:  os.Stderr,
-		configPath: args.configPath,
+		outWriter:       os.Stdout,
+		errWriter:       os.Stderr,
+		configPath:      args.configPath,
+		noDaemon:        args.noDaemon,
+		noProjectConfig: args.noProjectConfig,
+		profile:         args.profile,
+		outputDir:       args.outputDir,
+		browserCommand:  args.browserCommand,
+		themeName:       args.themeName,
+		printMode:       args.printMode,
 	}
 
 	if args.showList {
-		return c.listFiles()
+		return c.listFiles(args.list)
 	}
 
 	return c.run(args.filePath, args.watchMode)
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/masawada/mdp/internal/config"
+	"github.com/masawada/mdp/internal/renderer"
+)
+
+const themeUsageMessage = `usage: mdp theme <command> [options]
+
+Commands:
+  list             list built-in and user themes
+  new <name>       create a new theme from the default theme
+  validate <name>  check a theme for errors
+
+Options:
+  --config <config-file>  path to config file`
+
+type themeArgs struct {
+	command    string
+	configPath string
+	name       string
+}
+
+func parseThemeArgs(args []string) (*themeArgs, error) {
+	if len(args) == 0 {
+		return nil, errors.New("theme command is required")
+	}
+
+	fs := flag.NewFlagSet("mdp theme", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", "", "path to config file")
+
+	command := args[0]
+	if command == "-h" || command == "--help" || command == "-help" {
+		return nil, errHelp
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, err
+	}
+
+	parsed := &themeArgs{
+		command:    command,
+		configPath: *configPath,
+	}
+
+	switch command {
+	case "list":
+		if fs.NArg() != 0 {
+			return nil, errors.New("theme list takes no arguments")
+		}
+	case "new", "validate":
+		if fs.NArg() != 1 {
+			return nil, fmt.Errorf("theme %s requires exactly one theme name", command)
+		}
+		parsed.name = fs.Arg(0)
+	default:
+		return nil, fmt.Errorf("unknown theme command: %s", command)
+	}
+
+	return parsed, nil
+}
+
+func (c *cli) runTheme(args []string) int {
+	parsed, err := parseThemeArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelp) {
+			_, _ = fmt.Fprintln(c.outWriter, themeUsageMessage)
+			return 0
+		}
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		_, _ = fmt.Fprintln(c.errWriter, themeUsageMessage)
+		return 1
+	}
+
+	cfg, err := config.Load(parsed.configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	switch parsed.command {
+	case "new":
+		return c.newTheme(cfg.ConfigDir, parsed.name)
+	case "validate":
+		return c.validateTheme(cfg.ConfigDir, parsed.name)
+	default:
+		return c.listThemes(cfg.ConfigDir)
+	}
+}
+
+func (c *cli) listThemes(configDir string) int {
+	themes, err := renderer.ListThemes(configDir)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: failed to list themes: %v\n", err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(c.outWriter, 0, 0, 2, ' ', 0)
+	for _, theme := range themes {
+		source := "built-in"
+		if !theme.BuiltIn() {
+			source = theme.Path
+			if theme.Overrides {
+				source += " (overrides built-in)"
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", theme.Name, source)
+	}
+	_ = tw.Flush()
+
+	return 0
+}
+
+func (c *cli) newTheme(configDir, name string) int {
+	path, err := renderer.NewTheme(configDir, name)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintf(c.outWriter, "Created: %s\n", path)
+	return 0
+}
+
+func (c *cli) validateTheme(configDir, name string) int {
+	theme, problems, err := renderer.ValidateTheme(configDir, name)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.errWriter, "error: %v\n", err)
+		return 1
+	}
+
+	source := theme.Path
+	if theme.BuiltIn() {
+		source = "built-in"
+	}
+
+	if len(problems) > 0 {
+		_, _ = fmt.Fprintf(c.errWriter, "theme %q (%s) has %d problem(s):\n", name, source, len(problems))
+		for _, problem := range problems {
+			_, _ = fmt.Fprintf(c.errWriter, "  %s\n", problem)
+		}
+		return 1
+	}
+
+	_, _ = fmt.Fprintf(c.outWriter, "theme %q (%s) is valid\n", name, source)
+	return 0
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseThemeArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   *themeArgs
+		wantErrMsg string
+	}{
+		{
+			name:     "list",
+			args:     []string{"list"},
+			wantArgs: &themeArgs{command: "list"},
+		},
+		{
+			name:     "new with config",
+			args:     []string{"new", "--config", "config.yaml", "mine"},
+			wantArgs: &themeArgs{command: "new", configPath: "config.yaml", name: "mine"},
+		},
+		{
+			name:     "validate",
+			args:     []string{"validate", "mine"},
+			wantArgs: &themeArgs{command: "validate", name: "mine"},
+		},
+		{
+			name:       "no command",
+			args:       []string{},
+			wantErrMsg: "theme command is required",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"remove", "mine"},
+			wantErrMsg: "unknown theme command",
+		},
+		{
+			name:       "new without name",
+			args:       []string{"new"},
+			wantErrMsg: "requires exactly one theme name",
+		},
+		{
+			name:       "help",
+			args:       []string{"--help"},
+			wantErrMsg: "help requested",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseThemeArgs(tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("parseThemeArgs() error = %v, want error containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseThemeArgs() unexpected error = %v", err)
+			}
+			if *got != *tt.wantArgs {
+				t.Errorf("parseThemeArgs() = %+v, want %+v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRunTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := fmt.Sprintf("output_dir: %s\n", filepath.Join(tmpDir, "output"))
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	run := func(command string, names ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		c := &cli{
+			outWriter: &stdout,
+			errWriter: &stderr,
+		}
+		args := append([]string{command, "--config", configFile}, names...)
+		exitCode := c.runTheme(args)
+		return exitCode, stdout.String(), stderr.String()
+	}
+
+	exitCode, stdout, stderr := run("new", "mine")
+	if exitCode != 0 {
+		t.Fatalf("theme new exit code = %d, stderr: %s", exitCode, stderr)
+	}
+	themePath := filepath.Join(tmpDir, "themes", "mine.html")
+	if !strings.Contains(stdout, themePath) {
+		t.Errorf("theme new stdout = %q, want path %q", stdout, themePath)
+	}
+
+	exitCode, stdout, _ = run("list")
+	if exitCode != 0 {
+		t.Fatalf("theme list exit code = %d", exitCode)
+	}
+	if !strings.Contains(stdout, "default  built-in") || !strings.Contains(stdout, themePath) {
+		t.Errorf("theme list stdout = %q", stdout)
+	}
+
+	exitCode, stdout, _ = run("validate", "mine")
+	if exitCode != 0 || !strings.Contains(stdout, "is valid") {
+		t.Errorf("theme validate exit code = %d, stdout = %q", exitCode, stdout)
+	}
+
+	if err := os.WriteFile(themePath, []byte("{{.Contnet}}"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	exitCode, _, stderr = run("validate", "mine")
+	if exitCode != 1 || !strings.Contains(stderr, "unknown field .Contnet") {
+		t.Errorf("theme validate exit code = %d, stderr = %q", exitCode, stderr)
+	}
+}
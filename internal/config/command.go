@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/masawada/mdp/internal/shellwords"
+	"gopkg.in/yaml.v3"
+)
+
+// Command is a command line split into the program and its arguments. In
+// YAML it is either a string, split using shell quoting rules, or a list of
+// arguments used as is.
+type Command []string
+
+// ParseCommand splits s into a Command using shell quoting rules.
+func ParseCommand(s string) (Command, error) {
+	words, err := shellwords.Split(s)
+	if err != nil {
+		return nil, err
+	}
+	return Command(words), nil
+}
+
+// String returns the command quoted so that ParseCommand reverses it.
+func (c Command) String() string {
+	return shellwords.Join(c)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *Command) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var s string
+		if err := node.Decode(&s); err != nil {
+			return err
+		}
+		command, err := ParseCommand(s)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid command %q: %w", node.Line, s, err)
+		}
+		*c = command
+	case yaml.SequenceNode:
+		var words []string
+		if err := node.Decode(&words); err != nil {
+			return err
+		}
+		*c = words
+	default:
+		return fmt.Errorf("line %d: command must be a string or a list of strings", node.Line)
+	}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (c Command) MarshalYAML() (any, error) {
+	return c.String(), nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCommand_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    Command
+		wantErr string
+	}{
+		{name: "single word", yaml: "cmd: firefox", want: Command{"firefox"}},
+		{name: "string with arguments", yaml: `cmd: firefox --new-window {url}`, want: Command{"firefox", "--new-window", "{url}"}},
+		{name: "string with quotes", yaml: `cmd: "open -a 'Google Chrome' {path}"`, want: Command{"open", "-a", "Google Chrome", "{path}"}},
+		{name: "list", yaml: "cmd:\n  - open\n  - -a\n  - Google Chrome\n", want: Command{"open", "-a", "Google Chrome"}},
+		{name: "empty string", yaml: `cmd: ""`, want: Command{}},
+		{name: "unterminated quote", yaml: `cmd: "firefox 'x"`, wantErr: "line 1: invalid command"},
+		{name: "mapping", yaml: "cmd:\n  a: b\n", wantErr: "must be a string or a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				Cmd Command `yaml:"cmd"`
+			}
+			err := yaml.Unmarshal([]byte(tt.yaml), &v)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() returned error: %v", err)
+			}
+			if !slices.Equal(v.Cmd, tt.want) {
+				t.Errorf("Cmd = %q, want %q", v.Cmd, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand_MarshalYAML(t *testing.T) {
+	out, err := yaml.Marshal(Command{"open", "-a", "Google Chrome", "{path}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(out)), `open -a 'Google Chrome' {path}`; got != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,26 +17,64 @@ import (
 var userHomeDir = os.UserHomeDir
 var userConfigDir = os.UserConfigDir
 var goos = runtime.GOOS
+var getenv = os.Getenv
+
+var procVersionPath = "/proc/version"
 
 // DefaultOutputDir returns the default output directory path.
-func DefaultOutputDir() string {
+func DefaultOutputDir() (string, error) {
 	homeDir, err := userHomeDir()
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
 	}
-	return filepath.Join(homeDir, ".mdp")
+	return filepath.Join(homeDir, ".mdp"), nil
 }
 
-// DefaultBrowserCommand returns the default browser command for the current OS.
+// DefaultBrowserCommand returns the default browser command for the current
+// environment. $BROWSER is honored when set. It returns an empty string when
+// no browser can be opened, such as in a headless session or on an
+// unsupported platform.
 func DefaultBrowserCommand() string {
+	if browser := getenv("BROWSER"); browser != "" {
+		// $BROWSER may list several commands separated by colons.
+		first, _, _ := strings.Cut(browser, ":")
+		return first
+	}
+
 	switch goos {
 	case "darwin":
 		return "open"
 	case "linux":
-		return "xdg-open"
+		if isWSL() {
+			return "wslview"
+		}
+		return xdgOpen()
+	case "dragonfly", "freebsd", "netbsd", "openbsd":
+		return xdgOpen()
 	default:
-		panic(fmt.Sprintf("unsupported platform: %s", goos))
+		return ""
+	}
+}
+
+// xdgOpen returns xdg-open if a graphical session is available.
+func xdgOpen() string {
+	if getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+		return ""
+	}
+	return "xdg-open"
+}
+
+// isWSL reports whether mdp runs under Windows Subsystem for Linux, where
+// the Windows browser is opened with wslview.
+func isWSL() bool {
+	if getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile(procVersionPath)
+	if err != nil {
+		return false
 	}
+	return strings.Contains(strings.ToLower(string(version)), "microsoft")
 }
 
 func expandTilde(path string) (string, error) {
@@ -76,56 +115,303 @@ func resolveConfigPath() string {
 	return ""
 }
 
+// DefaultBrowserTimeout is how long mdp waits for the browser command to
+// exit before leaving it running in the background.
+const DefaultBrowserTimeout = 2 * time.Second
+
+// Color schemes accepted by the color_scheme setting.
+const (
+	ColorSchemeAuto  = "auto"
+	ColorSchemeLight = "light"
+	ColorSchemeDark  = "dark"
+)
+
+// Output layouts accepted by the output_layout setting.
+const (
+	OutputLayoutMirror  = "mirror"
+	OutputLayoutFlat    = "flat"
+	OutputLayoutProject = "project"
+)
+
+// Output permissions accepted by the output_permissions setting.
+const (
+	OutputPermissionsShared  = "shared"
+	OutputPermissionsPrivate = "private"
+)
+
+// Raw HTML policies accepted by the raw_html setting.
+const (
+	RawHTMLOmit     = "omit"
+	RawHTMLSanitize = "sanitize"
+	RawHTMLAllow    = "allow"
+)
+
 // Config holds the application configuration.
 type Config struct {
-	OutputDir      string `yaml:"output_dir"`
-	BrowserCommand string `yaml:"browser_command"`
-	Theme          string `yaml:"theme"`
-	ConfigDir      string `yaml:"-"`
+	OutputDir         string              `yaml:"output_dir"`
+	OutputLayout      string              `yaml:"output_layout"`
+	OutputPermissions string              `yaml:"output_permissions"`
+	BrowserCommand    Command             `yaml:"browser_command"`
+	BrowserTimeout    Duration            `yaml:"browser_timeout"`
+	Theme             string              `yaml:"theme"`
+	ColorScheme       string              `yaml:"color_scheme"`
+	ColorSchemeToggle bool                `yaml:"color_scheme_toggle"`
+	PrintCSS          string              `yaml:"print_css"`
+	RawHTML           string              `yaml:"raw_html"`
+	Markdown          Markdown            `yaml:"markdown"`
+	Emoji             Emoji               `yaml:"emoji"`
+	Profiles          map[string]*Profile `yaml:"profiles"`
+	ConfigDir         string              `yaml:"-"`
+	// Path is the user config file that was loaded, or empty if none was found.
+	Path string `yaml:"-"`
+	// ProjectPath is the project config file that was loaded, or empty if none was found.
+	ProjectPath string `yaml:"-"`
+	// ActiveProfile is the name of the selected profile, or empty if none.
+	ActiveProfile string `yaml:"-"`
+	// Sources maps each key set by a config source to a description of that
+	// source. Keys left at their defaults are absent.
+	Sources map[string]string `yaml:"-"`
+}
+
+// Options controls which configuration sources are loaded.
+type Options struct {
+	// Path is an explicit config file. When empty, the user config
+	// candidates are searched.
+	Path string
+	// Dir is the directory project config discovery starts from, usually the
+	// directory of the markdown file. Discovery is skipped when Dir is empty.
+	Dir string
+	// NoProjectConfig disables project config discovery.
+	NoProjectConfig bool
+	// Profile selects a profile defined under "profiles". When empty, the
+	// MDP_PROFILE environment variable is consulted.
+	Profile string
+	// Overrides are applied last, typically from command-line flags.
+	Overrides []Override
+	// Env, when not nil, replaces the process environment as the source of
+	// the MDP_* variables, such as those of another mdp process returned by
+	// Environ.
+	Env map[string]string
 }
 
 // Load loads the configuration from the specified path or the default location.
 func Load(path string) (*Config, error) {
+	return LoadWithOptions(Options{Path: path})
+}
+
+// LoadWithOptions loads the configuration from all sources and merges them.
+// From highest to lowest precedence, the sources are opts.Overrides, MDP_*
+// environment variables, the selected profile, the nearest project config
+// file, the user config file and the defaults.
+func LoadWithOptions(opts Options) (*Config, error) {
 	cfg := &Config{
-		OutputDir:      DefaultOutputDir(),
-		BrowserCommand: DefaultBrowserCommand(),
+		BrowserTimeout: Duration(DefaultBrowserTimeout),
+		ColorScheme:    ColorSchemeAuto,
+		Sources:        make(map[string]string),
 	}
 
+	path := opts.Path
 	if path == "" {
 		path = resolveConfigPath()
-		if path == "" {
-			// No config file found, use default ConfigDir
-			cfg.ConfigDir = filepath.Dir(configPathCandidates()[0])
-			return cfg, nil
+	}
+
+	if path == "" {
+		// No config file found, use default ConfigDir
+		if candidates := configPathCandidates(); len(candidates) > 0 {
+			cfg.ConfigDir = filepath.Dir(candidates[0])
+		}
+	} else {
+		cfg.ConfigDir = filepath.Dir(path)
+		loaded, err := cfg.applyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if loaded {
+			cfg.Path = path
 		}
 	}
 
-	cfg.ConfigDir = filepath.Dir(path)
+	lookupEnv := getenv
+	if opts.Env != nil {
+		lookupEnv = func(name string) string { return opts.Env[name] }
+	}
+
+	if opts.Dir != "" && !opts.NoProjectConfig && lookupEnv(NoProjectConfigEnv) == "" {
+		if projectPath := findProjectConfig(opts.Dir); projectPath != "" {
+			if err := cfg.applyProjectFile(projectPath); err != nil {
+				return nil, err
+			}
+			cfg.ProjectPath = projectPath
+		}
+	}
+
+	profile := opts.Profile
+	if profile == "" {
+		profile = lookupEnv(ProfileEnv)
+	}
+	if profile != "" {
+		if err := cfg.applyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+	for _, override := range opts.Overrides {
+		if err := cfg.applyOverride(override); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
 
+// applyFile merges the config file at path into cfg. It reports false
+// without error if the file does not exist.
+func (cfg *Config) applyFile(path string) (bool, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // G304: path is user-specified config file
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return cfg, nil
+			return false, nil
 		}
-		return nil, err
+		return false, err
 	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, err
+	if err := cfg.apply(data, path, filepath.Dir(path)); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	return true, nil
+}
+
+// apply merges YAML data into cfg and records source as the origin of
+// every key it sets. Relative paths are resolved against baseDir.
+func (cfg *Config) apply(data []byte, source, baseDir string) error {
+	if err := decode(data, cfg); err != nil {
+		return err
+	}
+	for name, profile := range cfg.Profiles {
+		if profile == nil {
+			// An empty profile, such as "review:" with no values.
+			profile = &Profile{values: &yaml.Node{Kind: yaml.MappingNode}}
+			cfg.Profiles[name] = profile
+		}
+		if profile.source == "" {
+			profile.source = source
+		}
 	}
 
+	keys, err := topLevelKeys(data)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := cfg.record(key, source, baseDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// record notes source as the origin of key, which has just been set, and
+// resolves the value against baseDir if it is a path.
+func (cfg *Config) record(key, source, baseDir string) error {
+	cfg.Sources[key] = source
+	if key == "print_css" && cfg.PrintCSS != "" {
+		resolved, err := resolvePath(cfg.PrintCSS, baseDir)
+		if err != nil {
+			return fmt.Errorf("failed to expand print_css: %w", err)
+		}
+		cfg.PrintCSS = resolved
+	}
+	return nil
+}
+
+// resolvePath expands a leading tilde in path and makes it absolute
+// relative to baseDir, or to the working directory if baseDir is empty.
+func resolvePath(path, baseDir string) (string, error) {
+	expanded, err := expandTilde(path)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(expanded) {
+		return expanded, nil
+	}
+	if baseDir == "" {
+		return filepath.Abs(expanded)
+	}
+	return filepath.Join(baseDir, expanded), nil
+}
+
+// normalize fills in defaults for values left empty, expands paths and
+// rejects values outside their allowed set.
+func (cfg *Config) normalize() error {
 	if cfg.OutputDir == "" {
-		cfg.OutputDir = DefaultOutputDir()
+		outputDir, err := DefaultOutputDir()
+		if err != nil {
+			return fmt.Errorf("output_dir is not set and the default is unavailable: %w", err)
+		}
+		cfg.OutputDir = outputDir
 	} else {
 		expanded, err := expandTilde(cfg.OutputDir)
 		if err != nil {
-			return nil, fmt.Errorf("failed to expand output_dir: %w", err)
+			return fmt.Errorf("%s: failed to expand output_dir: %w", cfg.Sources["output_dir"], err)
 		}
 		cfg.OutputDir = expanded
 	}
-	if cfg.BrowserCommand == "" {
-		cfg.BrowserCommand = DefaultBrowserCommand()
+	if len(cfg.BrowserCommand) == 0 {
+		if browser := DefaultBrowserCommand(); browser != "" {
+			command, err := ParseCommand(browser)
+			if err != nil {
+				// $BROWSER is not valid shell syntax; treat it as a program path.
+				command = Command{browser}
+			}
+			cfg.BrowserCommand = command
+		}
+	}
+	if cfg.BrowserTimeout < 0 {
+		return fmt.Errorf("%s: invalid browser_timeout %s: must not be negative", cfg.Sources["browser_timeout"], time.Duration(cfg.BrowserTimeout))
+	}
+	switch cfg.OutputLayout {
+	case "":
+		cfg.OutputLayout = OutputLayoutMirror
+	case OutputLayoutMirror, OutputLayoutFlat, OutputLayoutProject:
+	default:
+		return fmt.Errorf("%s: invalid output_layout %q: must be one of mirror, flat, project", cfg.Sources["output_layout"], cfg.OutputLayout)
+	}
+	switch cfg.OutputPermissions {
+	case "":
+		cfg.OutputPermissions = OutputPermissionsShared
+	case OutputPermissionsShared, OutputPermissionsPrivate:
+	default:
+		return fmt.Errorf("%s: invalid output_permissions %q: must be one of shared, private", cfg.Sources["output_permissions"], cfg.OutputPermissions)
+	}
+	switch cfg.ColorScheme {
+	case "":
+		cfg.ColorScheme = ColorSchemeAuto
+	case ColorSchemeAuto, ColorSchemeLight, ColorSchemeDark:
+	default:
+		return fmt.Errorf("%s: invalid color_scheme %q: must be one of auto, light, dark", cfg.Sources["color_scheme"], cfg.ColorScheme)
 	}
 
-	return cfg, nil
+	switch cfg.RawHTML {
+	case "":
+		cfg.RawHTML = RawHTMLOmit
+	case RawHTMLOmit, RawHTMLSanitize, RawHTMLAllow:
+	default:
+		return fmt.Errorf("%s: invalid raw_html %q: must be one of omit, sanitize, allow", cfg.Sources["raw_html"], cfg.RawHTML)
+	}
+	if err := cfg.Markdown.normalize(cfg.Sources["markdown"]); err != nil {
+		return err
+	}
+	if err := cfg.Emoji.normalize(cfg.Sources["emoji"]); err != nil {
+		return err
+	}
+
+	return nil
 }
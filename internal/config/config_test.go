@@ -4,7 +4,9 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultOutputDir(t *testing.T) {
@@ -14,13 +16,16 @@ func TestDefaultOutputDir(t *testing.T) {
 			t.Fatal(err)
 		}
 		expected := filepath.Join(homeDir, ".mdp")
-		actual := DefaultOutputDir()
+		actual, err := DefaultOutputDir()
+		if err != nil {
+			t.Fatalf("DefaultOutputDir() returned error: %v", err)
+		}
 		if actual != expected {
 			t.Errorf("DefaultOutputDir() = %q, want %q", actual, expected)
 		}
 	})
 
-	t.Run("panics when UserHomeDir fails", func(t *testing.T) {
+	t.Run("returns error when UserHomeDir fails", func(t *testing.T) {
 		original := userHomeDir
 		defer func() { userHomeDir = original }()
 
@@ -28,53 +33,68 @@ func TestDefaultOutputDir(t *testing.T) {
 			return "", errors.New("$HOME is not defined")
 		}
 
-		defer func() {
-			if r := recover(); r == nil {
-				t.Error("DefaultOutputDir() should panic when UserHomeDir fails")
-			}
-		}()
-
-		DefaultOutputDir()
-	})
-}
-
-func TestDefaultBrowserCommand(t *testing.T) {
-	t.Run("returns open on darwin", func(t *testing.T) {
-		original := goos
-		defer func() { goos = original }()
-
-		goos = "darwin"
-		cmd := DefaultBrowserCommand()
-		if cmd != "open" {
-			t.Errorf("DefaultBrowserCommand() = %q, want \"open\"", cmd)
+		_, err := DefaultOutputDir()
+		if err == nil {
+			t.Fatal("DefaultOutputDir() should return error when UserHomeDir fails")
 		}
-	})
-
-	t.Run("returns xdg-open on linux", func(t *testing.T) {
-		original := goos
-		defer func() { goos = original }()
-
-		goos = "linux"
-		cmd := DefaultBrowserCommand()
-		if cmd != "xdg-open" {
-			t.Errorf("DefaultBrowserCommand() = %q, want \"xdg-open\"", cmd)
+		if !strings.Contains(err.Error(), "$HOME is not defined") {
+			t.Errorf("error = %q, want it to contain the cause", err)
 		}
 	})
+}
 
-	t.Run("panics on unsupported platform", func(t *testing.T) {
-		original := goos
-		defer func() { goos = original }()
-
-		goos = "windows"
-
-		defer func() {
-			if r := recover(); r == nil {
-				t.Error("DefaultBrowserCommand() should panic on unsupported platform")
+func TestDefaultBrowserCommand(t *testing.T) {
+	originalGoos := goos
+	originalGetenv := getenv
+	originalProcVersion := procVersionPath
+	defer func() {
+		goos = originalGoos
+		getenv = originalGetenv
+		procVersionPath = originalProcVersion
+	}()
+
+	nativeLinux := filepath.Join(t.TempDir(), "version")
+	if err := os.WriteFile(nativeLinux, []byte("Linux version 6.8.0-generic (gcc)\n"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	wslLinux := filepath.Join(t.TempDir(), "version")
+	if err := os.WriteFile(wslLinux, []byte("Linux version 5.15.153.1-microsoft-standard-WSL2\n"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		goos        string
+		env         map[string]string
+		procVersion string
+		want        string
+	}{
+		{name: "open on darwin", goos: "darwin", want: "open"},
+		{name: "xdg-open on linux with X11", goos: "linux", env: map[string]string{"DISPLAY": ":0"}, procVersion: nativeLinux, want: "xdg-open"},
+		{name: "xdg-open on linux with Wayland", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, procVersion: nativeLinux, want: "xdg-open"},
+		{name: "empty on headless linux", goos: "linux", procVersion: nativeLinux, want: ""},
+		{name: "wslview on WSL detected from /proc/version", goos: "linux", procVersion: wslLinux, want: "wslview"},
+		{name: "wslview on WSL detected from WSL_DISTRO_NAME", goos: "linux", env: map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, procVersion: nativeLinux, want: "wslview"},
+		{name: "xdg-open on freebsd with X11", goos: "freebsd", env: map[string]string{"DISPLAY": ":0"}, want: "xdg-open"},
+		{name: "empty on unsupported platform", goos: "windows", want: ""},
+		{name: "BROWSER takes precedence", goos: "darwin", env: map[string]string{"BROWSER": "firefox"}, want: "firefox"},
+		{name: "BROWSER uses first of colon-separated list", goos: "windows", env: map[string]string{"BROWSER": "w3m:lynx"}, want: "w3m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goos = tt.goos
+			getenv = func(key string) string { return tt.env[key] }
+			procVersionPath = tt.procVersion
+			if procVersionPath == "" {
+				procVersionPath = filepath.Join(t.TempDir(), "missing")
 			}
-		}()
 
-		DefaultBrowserCommand()
-	})
+			if got := DefaultBrowserCommand(); got != tt.want {
+				t.Errorf("DefaultBrowserCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestConfigPathCandidates(t *testing.T) {
@@ -203,11 +223,107 @@ func TestLoad(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Load() returned error: %v", err)
 		}
-		if cfg.OutputDir != DefaultOutputDir() {
-			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, DefaultOutputDir())
+		if want, _ := DefaultOutputDir(); cfg.OutputDir != want {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, want)
 		}
-		if cfg.BrowserCommand != DefaultBrowserCommand() {
-			t.Errorf("BrowserCommand = %q, want %q", cfg.BrowserCommand, DefaultBrowserCommand())
+		if got, want := cfg.BrowserCommand.String(), DefaultBrowserCommand(); got != want {
+			t.Errorf("BrowserCommand = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("without home directory uses configured output_dir", func(t *testing.T) {
+		originalHome, originalConfig := userHomeDir, userConfigDir
+		defer func() { userHomeDir, userConfigDir = originalHome, originalConfig }()
+		userHomeDir = func() (string, error) { return "", errors.New("$HOME is not defined") }
+		userConfigDir = func() (string, error) { return "", errors.New("neither $XDG_CONFIG_HOME nor $HOME are defined") }
+
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configFile, []byte("output_dir: /custom/output\n"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if cfg.OutputDir != "/custom/output" {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/custom/output")
+		}
+	})
+
+	t.Run("without home directory and output_dir returns error", func(t *testing.T) {
+		originalHome, originalConfig := userHomeDir, userConfigDir
+		defer func() { userHomeDir, userConfigDir = originalHome, originalConfig }()
+		userHomeDir = func() (string, error) { return "", errors.New("$HOME is not defined") }
+		userConfigDir = func() (string, error) { return "", errors.New("neither $XDG_CONFIG_HOME nor $HOME are defined") }
+
+		_, err := Load("")
+		if err == nil {
+			t.Fatal("Load() should return error when no output_dir can be determined")
+		}
+		if !strings.Contains(err.Error(), "output_dir") {
+			t.Errorf("error = %q, want it to mention output_dir", err)
+		}
+	})
+
+	t.Run("browser_timeout", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			want    time.Duration
+			wantErr string
+		}{
+			{name: "default", content: "", want: DefaultBrowserTimeout},
+			{name: "custom", content: "browser_timeout: 10s\n", want: 10 * time.Second},
+			{name: "zero disables waiting", content: "browser_timeout: 0s\n", want: 0},
+			{name: "negative", content: "browser_timeout: -1s\n", wantErr: "must not be negative"},
+			{name: "missing unit", content: "browser_timeout: 10\n", wantErr: `invalid duration "10"`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				configFile := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil { //nolint:gosec // G306: test file
+					t.Fatal(err)
+				}
+				cfg, err := Load(configFile)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Load() returned error: %v", err)
+				}
+				if got := time.Duration(cfg.BrowserTimeout); got != tt.want {
+					t.Errorf("BrowserTimeout = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("browser_command accepts a list", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		content := []byte("browser_command:\n  - open\n  - -a\n  - Google Chrome\n  - \"{url}\"\n")
+		if err := os.WriteFile(configFile, content, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if got, want := cfg.BrowserCommand.String(), "open -a 'Google Chrome' {url}"; got != want {
+			t.Errorf("BrowserCommand = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("browser_command with unterminated quote returns error", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configFile, []byte("browser_command: firefox 'x\n"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		_, err := Load(configFile)
+		if err == nil || !strings.Contains(err.Error(), "unterminated quote") {
+			t.Errorf("Load() error = %v, want unterminated quote", err)
 		}
 	})
 
@@ -216,8 +332,8 @@ func TestLoad(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Load() returned error: %v", err)
 		}
-		if cfg.OutputDir != DefaultOutputDir() {
-			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, DefaultOutputDir())
+		if want, _ := DefaultOutputDir(); cfg.OutputDir != want {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, want)
 		}
 	})
 
@@ -236,8 +352,8 @@ func TestLoad(t *testing.T) {
 		if cfg.OutputDir != "/custom/output" {
 			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/custom/output")
 		}
-		if cfg.BrowserCommand != "firefox" {
-			t.Errorf("BrowserCommand = %q, want %q", cfg.BrowserCommand, "firefox")
+		if got := cfg.BrowserCommand.String(); got != "firefox" {
+			t.Errorf("BrowserCommand = %q, want %q", got, "firefox")
 		}
 	})
 
@@ -289,6 +405,188 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("color_scheme defaults to auto", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := []byte("output_dir: /custom/output\n")
+		if err := os.WriteFile(configFile, content, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if cfg.ColorScheme != ColorSchemeAuto {
+			t.Errorf("ColorScheme = %q, want %q", cfg.ColorScheme, ColorSchemeAuto)
+		}
+		if cfg.ColorSchemeToggle {
+			t.Error("ColorSchemeToggle = true, want false")
+		}
+	})
+
+	t.Run("color_scheme fields are loaded correctly", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := []byte("color_scheme: dark\ncolor_scheme_toggle: true\n")
+		if err := os.WriteFile(configFile, content, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if cfg.ColorScheme != ColorSchemeDark {
+			t.Errorf("ColorScheme = %q, want %q", cfg.ColorScheme, ColorSchemeDark)
+		}
+		if !cfg.ColorSchemeToggle {
+			t.Error("ColorSchemeToggle = false, want true")
+		}
+	})
+
+	t.Run("invalid color_scheme returns error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := []byte("color_scheme: sepia\n")
+		if err := os.WriteFile(configFile, content, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		_, err := Load(configFile)
+		if err == nil {
+			t.Error("Load() should return error for invalid color_scheme")
+		}
+	})
+
+	t.Run("output_layout", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			content    string
+			wantLayout string
+			wantErr    bool
+		}{
+			{name: "default", content: "", wantLayout: OutputLayoutMirror},
+			{name: "flat", content: "output_layout: flat\n", wantLayout: OutputLayoutFlat},
+			{name: "project", content: "output_layout: project\n", wantLayout: OutputLayoutProject},
+			{name: "invalid", content: "output_layout: nested\n", wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				configFile := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil { //nolint:gosec // G306: test file
+					t.Fatal(err)
+				}
+
+				cfg, err := Load(configFile)
+				if tt.wantErr {
+					if err == nil || !strings.Contains(err.Error(), "invalid output_layout") {
+						t.Errorf("Load() error = %v, want invalid output_layout", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Load() returned error: %v", err)
+				}
+				if cfg.OutputLayout != tt.wantLayout {
+					t.Errorf("OutputLayout = %q, want %q", cfg.OutputLayout, tt.wantLayout)
+				}
+			})
+		}
+	})
+
+	t.Run("output_permissions", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			content         string
+			wantPermissions string
+			wantErr         bool
+		}{
+			{name: "default", content: "", wantPermissions: OutputPermissionsShared},
+			{name: "private", content: "output_permissions: private\n", wantPermissions: OutputPermissionsPrivate},
+			{name: "invalid", content: "output_permissions: 0600\n", wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				configFile := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil { //nolint:gosec // G306: test file
+					t.Fatal(err)
+				}
+
+				cfg, err := Load(configFile)
+				if tt.wantErr {
+					if err == nil || !strings.Contains(err.Error(), "invalid output_permissions") {
+						t.Errorf("Load() error = %v, want invalid output_permissions", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Load() returned error: %v", err)
+				}
+				if cfg.OutputPermissions != tt.wantPermissions {
+					t.Errorf("OutputPermissions = %q, want %q", cfg.OutputPermissions, tt.wantPermissions)
+				}
+			})
+		}
+	})
+
+	t.Run("raw_html", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			want    string
+			wantErr bool
+		}{
+			{name: "default", content: "", want: RawHTMLOmit},
+			{name: "sanitize", content: "raw_html: sanitize\n", want: RawHTMLSanitize},
+			{name: "allow", content: "raw_html: allow\n", want: RawHTMLAllow},
+			{name: "invalid", content: "raw_html: strip\n", wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				configFile := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil { //nolint:gosec // G306: test file
+					t.Fatal(err)
+				}
+
+				cfg, err := Load(configFile)
+				if tt.wantErr {
+					if err == nil || !strings.Contains(err.Error(), "invalid raw_html") {
+						t.Errorf("Load() error = %v, want invalid raw_html", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Load() returned error: %v", err)
+				}
+				if cfg.RawHTML != tt.want {
+					t.Errorf("RawHTML = %q, want %q", cfg.RawHTML, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("relative print_css is resolved against config directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		content := []byte("print_css: print/custom.css\n")
+		if err := os.WriteFile(configFile, content, 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		expected := filepath.Join(tmpDir, "print", "custom.css")
+		if cfg.PrintCSS != expected {
+			t.Errorf("PrintCSS = %q, want %q", cfg.PrintCSS, expected)
+		}
+	})
+
 	t.Run("configDir is set to config file directory", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		configFile := filepath.Join(tmpDir, "config.yaml")
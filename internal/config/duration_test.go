@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDuration_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    Duration
+		wantErr string
+	}{
+		{name: "seconds", yaml: "d: 2s", want: Duration(2 * time.Second)},
+		{name: "milliseconds", yaml: "d: 500ms", want: Duration(500 * time.Millisecond)},
+		{name: "zero", yaml: "d: 0", want: 0},
+		{name: "missing unit", yaml: "d: 5", wantErr: `line 1: invalid duration "5"`},
+		{name: "list", yaml: "d: [1s]", wantErr: "duration must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				D Duration `yaml:"d"`
+			}
+			err := yaml.Unmarshal([]byte(tt.yaml), &v)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() returned error: %v", err)
+			}
+			if v.D != tt.want {
+				t.Errorf("D = %v, want %v", time.Duration(v.D), time.Duration(tt.want))
+			}
+		})
+	}
+}
+
+func TestDuration_MarshalYAML(t *testing.T) {
+	out, err := yaml.Marshal(Duration(1500 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "1.5s" {
+		t.Errorf("Marshal() = %q, want %q", got, "1.5s")
+	}
+}
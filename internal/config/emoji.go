@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// Emoji outputs accepted by emoji.output.
+const (
+	EmojiOutputUnicode = "unicode"
+	EmojiOutputImage   = "image"
+)
+
+// emojiShortcodePattern matches the names that can be written as :name:.
+var emojiShortcodePattern = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+
+// Emoji configures how emoji shortcodes such as :tada: are rendered.
+type Emoji struct {
+	// Output is unicode or image.
+	Output string `yaml:"output,omitempty"`
+	// Aliases maps shortcodes to the text to render, the URL of an image, or
+	// another shortcode such as ":+1:".
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+}
+
+func (e *Emoji) normalize(source string) error {
+	switch e.Output {
+	case "":
+		e.Output = EmojiOutputUnicode
+	case EmojiOutputUnicode, EmojiOutputImage:
+	default:
+		return fmt.Errorf("%s: invalid emoji.output %q: must be one of unicode, image", source, e.Output)
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Aliases)) {
+		if !emojiShortcodePattern.MatchString(name) {
+			return fmt.Errorf("%s: invalid emoji alias %q: must contain only letters, digits, _, + and -", source, name)
+		}
+		if e.Aliases[name] == "" {
+			return fmt.Errorf("%s: invalid emoji alias %q: must not be empty", source, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Emoji(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Emoji
+		wantErr string
+	}{
+		{name: "default", content: "", want: Emoji{Output: EmojiOutputUnicode}},
+		{name: "image", content: "emoji:\n  output: image\n", want: Emoji{Output: EmojiOutputImage}},
+		{
+			name:    "aliases",
+			content: "emoji:\n  aliases:\n    lgtm: \":+1:\"\n    shipit: https://example.com/shipit.png\n",
+			want: Emoji{Output: EmojiOutputUnicode, Aliases: map[string]string{
+				"lgtm":   ":+1:",
+				"shipit": "https://example.com/shipit.png",
+			}},
+		},
+		{name: "invalid output", content: "emoji:\n  output: svg\n", wantErr: "invalid emoji.output"},
+		{name: "invalid alias name", content: "emoji:\n  aliases:\n    \"ship it\": x\n", wantErr: `invalid emoji alias "ship it"`},
+		{name: "empty alias", content: "emoji:\n  aliases:\n    lgtm: \"\"\n", wantErr: `invalid emoji alias "lgtm"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, configFile, tt.content)
+
+			cfg, err := Load(configFile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			if cfg.Emoji.Output != tt.want.Output {
+				t.Errorf("Emoji.Output = %q, want %q", cfg.Emoji.Output, tt.want.Output)
+			}
+			if !maps.Equal(cfg.Emoji.Aliases, tt.want.Aliases) {
+				t.Errorf("Emoji.Aliases = %v, want %v", cfg.Emoji.Aliases, tt.want.Aliases)
+			}
+		})
+	}
+
+	t.Run("project config adds aliases", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		userConfig := filepath.Join(tmpDir, "config.yaml")
+		writeFile(t, userConfig, "emoji:\n  aliases:\n    lgtm: \":+1:\"\n")
+		projectDir := filepath.Join(tmpDir, "project")
+		if err := os.MkdirAll(filepath.Join(projectDir, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(projectDir, ".mdp.yaml"), "emoji:\n  aliases:\n    shipit: https://example.com/shipit.png\n")
+
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: projectDir})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		want := map[string]string{"lgtm": ":+1:", "shipit": "https://example.com/shipit.png"}
+		if !maps.Equal(cfg.Emoji.Aliases, want) {
+			t.Errorf("Emoji.Aliases = %v, want %v", cfg.Emoji.Aliases, want)
+		}
+	})
+}
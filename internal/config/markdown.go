@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/masawada/mdp/internal/renderer"
+)
+
+// Markdown selects the Markdown syntax to support: a preset and features
+// turned on or off on top of it. Features left unset follow the preset.
+// Preset and feature names are those of the renderer package.
+type Markdown struct {
+	// Preset is commonmark, gfm or github-comment.
+	Preset string `yaml:"preset,omitempty"`
+	// Features turns features on or off by name, such as footnotes.
+	Features map[string]bool `yaml:",inline"`
+}
+
+// RendererFeatures returns the renderer features selected by m: its preset
+// with the features it turns on or off.
+func (m Markdown) RendererFeatures() (renderer.Features, error) {
+	features, err := renderer.Preset(m.Preset)
+	if err != nil {
+		return renderer.Features{}, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Features)) {
+		if err := features.Set(name, m.Features[name]); err != nil {
+			return renderer.Features{}, err
+		}
+	}
+	return features, nil
+}
+
+func (m *Markdown) normalize(source string) error {
+	if m.Preset == "" {
+		m.Preset = renderer.PresetGFM
+	}
+	if _, err := renderer.Preset(m.Preset); err != nil {
+		return fmt.Errorf("%s: invalid markdown.preset: %w", source, err)
+	}
+	if _, err := m.RendererFeatures(); err != nil {
+		return fmt.Errorf("%s: invalid markdown: %w", source, err)
+	}
+	return nil
+}
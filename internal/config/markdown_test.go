@@ -0,0 +1,117 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/masawada/mdp/internal/renderer"
+)
+
+func TestLoad_Markdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Markdown
+		wantErr bool
+	}{
+		{name: "default", content: "", want: Markdown{Preset: renderer.PresetGFM}},
+		{
+			name:    "preset",
+			content: "markdown:\n  preset: github-comment\n",
+			want:    Markdown{Preset: renderer.PresetGitHubComment},
+		},
+		{
+			name:    "features",
+			content: "markdown:\n  preset: commonmark\n  footnotes: true\n  linkify: false\n",
+			want:    Markdown{Preset: renderer.PresetCommonMark, Features: map[string]bool{"footnotes": true, "linkify": false}},
+		},
+		{name: "invalid preset", content: "markdown:\n  preset: markdown-it\n", wantErr: true},
+		{name: "invalid feature value", content: "markdown:\n  footnotes: sometimes\n", wantErr: true},
+		{name: "unknown feature", content: "markdown:\n  mermaid: true\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, configFile, tt.content)
+
+			cfg, err := Load(configFile)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Load() returned nil error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			assertMarkdown(t, cfg.Markdown, tt.want)
+		})
+	}
+
+	t.Run("project config and profile add to user config", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		userConfig := filepath.Join(tmpDir, "config.yaml")
+		writeFile(t, userConfig, "markdown:\n  preset: commonmark\n  footnotes: true\nprofiles:\n  notes:\n    markdown:\n      typographer: true\n")
+		projectDir := filepath.Join(tmpDir, "project")
+		if err := os.MkdirAll(filepath.Join(projectDir, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(projectDir, ".mdp.yaml"), "markdown:\n  hard_wraps: true\n")
+
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: projectDir, Profile: "notes"})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		assertMarkdown(t, cfg.Markdown, Markdown{
+			Preset:   renderer.PresetCommonMark,
+			Features: map[string]bool{"footnotes": true, "typographer": true, "hard_wraps": true},
+		})
+		if !strings.HasPrefix(cfg.Sources["markdown"], "profile notes") {
+			t.Errorf("Sources[markdown] = %q, want profile notes", cfg.Sources["markdown"])
+		}
+	})
+
+	t.Run("invalid preset names its source", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		writeFile(t, configFile, "markdown:\n  preset: markdown-it\n")
+
+		_, err := Load(configFile)
+		if err == nil || !strings.Contains(err.Error(), configFile) || !strings.Contains(err.Error(), "invalid markdown.preset") {
+			t.Errorf("Load() error = %v, want invalid markdown.preset from %s", err, configFile)
+		}
+	})
+}
+
+func TestMarkdown_RendererFeatures(t *testing.T) {
+	m := Markdown{Preset: renderer.PresetGitHubComment, Features: map[string]bool{"footnotes": true, "emoji": false}}
+	got, err := m.RendererFeatures()
+	if err != nil {
+		t.Fatalf("RendererFeatures() returned error: %v", err)
+	}
+	want, _ := renderer.Preset(renderer.PresetGitHubComment)
+	want.Footnotes = true
+	want.Emoji = false
+	if got != want {
+		t.Errorf("RendererFeatures() = %+v, want %+v", got, want)
+	}
+
+	m.Features["mermaid"] = true
+	if _, err := m.RendererFeatures(); err == nil || !strings.Contains(err.Error(), `unknown markdown feature "mermaid"`) {
+		t.Errorf("RendererFeatures() error = %v, want unknown feature", err)
+	}
+}
+
+func assertMarkdown(t *testing.T, got, want Markdown) {
+	t.Helper()
+
+	if got.Preset != want.Preset {
+		t.Errorf("Markdown.Preset = %q, want %q", got.Preset, want.Preset)
+	}
+	if !maps.Equal(got.Features, want.Features) {
+		t.Errorf("Markdown.Features = %v, want %v", got.Features, want.Features)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override sets a single config key, taking precedence over config files
+// and environment variables.
+type Override struct {
+	// Key is the config file key, such as "output_dir".
+	Key string
+	// Value is parsed the same way as a value in a config file.
+	Value string
+	// Source describes where the override came from, such as "flag --theme".
+	Source string
+}
+
+// EnvName returns the environment variable that overrides key, for example
+// MDP_OUTPUT_DIR for output_dir.
+func EnvName(key string) string {
+	return "MDP_" + strings.ToUpper(key)
+}
+
+// overridableKeys returns the keys that can be set from a single string,
+// which excludes sections such as profiles.
+func overridableKeys() []string {
+	var keys []string
+	typ := reflect.TypeFor[Config]()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if kind := field.Type.Kind(); kind == reflect.Map || kind == reflect.Struct {
+			continue
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// Environ returns the non-empty MDP_* environment variables that
+// LoadWithOptions reads, keyed by name.
+func Environ() map[string]string {
+	names := []string{NoProjectConfigEnv, ProfileEnv}
+	for _, key := range overridableKeys() {
+		names = append(names, EnvName(key))
+	}
+
+	env := make(map[string]string)
+	for _, name := range names {
+		if value := getenv(name); value != "" {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// applyEnv applies the MDP_* environment variables for every overridable
+// key, looked up with lookup. Variables that are unset or empty are ignored.
+func (cfg *Config) applyEnv(lookup func(string) string) error {
+	for _, key := range overridableKeys() {
+		name := EnvName(key)
+		value := lookup(name)
+		if value == "" {
+			continue
+		}
+		if err := cfg.applyOverride(Override{Key: key, Value: value, Source: "env " + name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOverride sets a single key. Relative paths are resolved against the
+// working directory.
+func (cfg *Config) applyOverride(override Override) error {
+	if !slices.Contains(overridableKeys(), override.Key) {
+		return fmt.Errorf("%s: unknown key %q", override.Source, override.Key)
+	}
+
+	// An untagged scalar node is resolved like a plain value in a file, so
+	// "true" becomes a bool and "/path" a string.
+	node := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: override.Key},
+			{Kind: yaml.ScalarNode, Value: override.Value},
+		},
+	}
+	if err := node.Decode(cfg); err != nil {
+		return fmt.Errorf("%s: invalid value %q for %s", override.Source, override.Value, override.Key)
+	}
+
+	return cfg.record(override.Key, override.Source, "")
+}
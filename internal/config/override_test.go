@@ -0,0 +1,148 @@
+package config
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("output_dir"); got != "MDP_OUTPUT_DIR" {
+		t.Errorf("EnvName() = %q, want %q", got, "MDP_OUTPUT_DIR")
+	}
+}
+
+func TestLoadWithOptions_Overrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	userConfig := filepath.Join(tmpDir, "config.yaml")
+	writeFile(t, userConfig, "output_dir: /user/output\ntheme: user-theme\n")
+
+	withEnv := func(t *testing.T, env map[string]string) {
+		t.Helper()
+		originalGetenv := getenv
+		t.Cleanup(func() { getenv = originalGetenv })
+		getenv = func(key string) string { return env[key] }
+	}
+
+	t.Run("environment variables override config file", func(t *testing.T) {
+		withEnv(t, map[string]string{
+			"MDP_THEME":               "env-theme",
+			"MDP_COLOR_SCHEME_TOGGLE": "true",
+			"MDP_PRINT_CSS":           "print.css",
+		})
+
+		cfg, err := LoadWithOptions(Options{Path: userConfig})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "env-theme" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "env-theme")
+		}
+		if !cfg.ColorSchemeToggle {
+			t.Error("ColorSchemeToggle = false, want true")
+		}
+		if !filepath.IsAbs(cfg.PrintCSS) {
+			t.Errorf("PrintCSS = %q, want absolute path", cfg.PrintCSS)
+		}
+		if cfg.OutputDir != "/user/output" {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/user/output")
+		}
+		if cfg.Sources["theme"] != "env MDP_THEME" {
+			t.Errorf("Sources[theme] = %q, want %q", cfg.Sources["theme"], "env MDP_THEME")
+		}
+	})
+
+	t.Run("overrides take precedence over environment variables", func(t *testing.T) {
+		withEnv(t, map[string]string{"MDP_THEME": "env-theme"})
+
+		cfg, err := LoadWithOptions(Options{
+			Path:      userConfig,
+			Overrides: []Override{{Key: "theme", Value: "flag-theme", Source: "flag --theme"}},
+		})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "flag-theme" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "flag-theme")
+		}
+		if cfg.Sources["theme"] != "flag --theme" {
+			t.Errorf("Sources[theme] = %q, want %q", cfg.Sources["theme"], "flag --theme")
+		}
+	})
+
+	t.Run("browser command flag is split into arguments", func(t *testing.T) {
+		withEnv(t, nil)
+
+		cfg, err := LoadWithOptions(Options{
+			Path:      userConfig,
+			Overrides: []Override{{Key: "browser_command", Value: "open -a 'Google Chrome' {path}", Source: "flag --browser"}},
+		})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		want := Command{"open", "-a", "Google Chrome", "{path}"}
+		if !slices.Equal(cfg.BrowserCommand, want) {
+			t.Errorf("BrowserCommand = %q, want %q", cfg.BrowserCommand, want)
+		}
+	})
+
+	t.Run("invalid value names its source", func(t *testing.T) {
+		withEnv(t, map[string]string{"MDP_COLOR_SCHEME_TOGGLE": "sometimes"})
+
+		_, err := LoadWithOptions(Options{Path: userConfig})
+		if err == nil || !strings.Contains(err.Error(), `env MDP_COLOR_SCHEME_TOGGLE: invalid value "sometimes"`) {
+			t.Errorf("LoadWithOptions() error = %v", err)
+		}
+	})
+
+	t.Run("Env replaces the process environment", func(t *testing.T) {
+		withEnv(t, map[string]string{"MDP_THEME": "env-theme", "MDP_OUTPUT_DIR": "/env/output"})
+
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Env: map[string]string{"MDP_THEME": "caller-theme"}})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "caller-theme" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "caller-theme")
+		}
+		if cfg.OutputDir != "/user/output" {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/user/output")
+		}
+	})
+
+	t.Run("unknown override key returns error", func(t *testing.T) {
+		withEnv(t, nil)
+
+		_, err := LoadWithOptions(Options{
+			Path:      userConfig,
+			Overrides: []Override{{Key: "nope", Value: "x", Source: "flag --nope"}},
+		})
+		if err == nil {
+			t.Error("LoadWithOptions() should return error for unknown key")
+		}
+	})
+}
+
+func TestEnviron(t *testing.T) {
+	originalGetenv := getenv
+	t.Cleanup(func() { getenv = originalGetenv })
+	env := map[string]string{
+		"MDP_THEME":             "env-theme",
+		"MDP_PROFILE":           "review",
+		"MDP_NO_PROJECT_CONFIG": "1",
+		"MDP_UNRELATED":         "x",
+		"HOME":                  "/home/user",
+	}
+	getenv = func(key string) string { return env[key] }
+
+	want := map[string]string{
+		"MDP_THEME":             "env-theme",
+		"MDP_PROFILE":           "review",
+		"MDP_NO_PROJECT_CONFIG": "1",
+	}
+	if got := Environ(); !maps.Equal(got, want) {
+		t.Errorf("Environ() = %v, want %v", got, want)
+	}
+}
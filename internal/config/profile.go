@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv is the environment variable that selects a profile when no
+// profile is given on the command line.
+const ProfileEnv = "MDP_PROFILE"
+
+// Profile is a named set of config values that overlays the base config
+// when selected.
+type Profile struct {
+	// Inherits names a profile whose values are applied before this one.
+	Inherits string
+
+	values *yaml.Node
+	source string
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Unknown keys are rejected here
+// so that typos are reported even for profiles that are not selected.
+func (p *Profile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: profile must be a mapping", node.Line)
+	}
+
+	values := &yaml.Node{Kind: yaml.MappingNode, Line: node.Line, Column: node.Column}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case key.Value == "inherits":
+			if err := value.Decode(&p.Inherits); err != nil {
+				return err
+			}
+		case key.Value == "profiles":
+			return fmt.Errorf("line %d: profiles cannot be nested", key.Line)
+		case !slices.Contains(knownKeys(), key.Value):
+			msg := fmt.Sprintf("line %d: unknown key %q in profile", key.Line, key.Value)
+			if suggestion := closestKey(key.Value); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			return fmt.Errorf("%s", msg)
+		default:
+			values.Content = append(values.Content, key, value)
+		}
+	}
+	p.values = values
+
+	return nil
+}
+
+// applyProfile applies the named profile, after the profiles it inherits
+// from, on top of the current values.
+func (cfg *Config) applyProfile(name string) error {
+	chain, err := cfg.profileChain(name)
+	if err != nil {
+		return err
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile := cfg.Profiles[chain[i]]
+		if err := profile.values.Decode(cfg); err != nil {
+			return fmt.Errorf("%s: profile %q: %w", profile.source, chain[i], err)
+		}
+
+		source := fmt.Sprintf("profile %s (%s)", chain[i], profile.source)
+		for j := 0; j < len(profile.values.Content); j += 2 {
+			if err := cfg.record(profile.values.Content[j].Value, source, filepath.Dir(profile.source)); err != nil {
+				return err
+			}
+		}
+	}
+
+	cfg.ActiveProfile = name
+	return nil
+}
+
+// profileChain returns name followed by the profiles it inherits from,
+// nearest first.
+func (cfg *Config) profileChain(name string) ([]string, error) {
+	var chain []string
+	for current := name; current != ""; {
+		if slices.Contains(chain, current) {
+			return nil, fmt.Errorf("profile %q: inheritance cycle: %s -> %s", name, strings.Join(chain, " -> "), current)
+		}
+
+		profile, ok := cfg.Profiles[current]
+		if !ok {
+			if current == name {
+				return nil, fmt.Errorf("unknown profile %q (%s)", name, cfg.availableProfiles())
+			}
+			return nil, fmt.Errorf("profile %q inherits unknown profile %q", chain[len(chain)-1], current)
+		}
+
+		chain = append(chain, current)
+		current = profile.Inherits
+	}
+	return chain, nil
+}
+
+func (cfg *Config) availableProfiles() string {
+	if len(cfg.Profiles) == 0 {
+		return "no profiles are defined"
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "available: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `output_dir: /base/output
+theme: base
+profiles:
+  authoring:
+    color_scheme: dark
+    color_scheme_toggle: true
+  review:
+    theme: print
+    print_css: review.css
+  review-dark:
+    inherits: review
+    color_scheme: dark
+`
+
+func TestLoadWithOptions_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	writeFile(t, configFile, profilesConfig)
+
+	t.Run("without profile uses base config", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: configFile})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "base" || cfg.ColorScheme != ColorSchemeAuto || cfg.ActiveProfile != "" {
+			t.Errorf("Theme = %q, ColorScheme = %q, ActiveProfile = %q", cfg.Theme, cfg.ColorScheme, cfg.ActiveProfile)
+		}
+	})
+
+	t.Run("profile overlays base config", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: configFile, Profile: "authoring"})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "base" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "base")
+		}
+		if cfg.ColorScheme != ColorSchemeDark || !cfg.ColorSchemeToggle {
+			t.Errorf("ColorScheme = %q, ColorSchemeToggle = %v", cfg.ColorScheme, cfg.ColorSchemeToggle)
+		}
+		if cfg.ActiveProfile != "authoring" {
+			t.Errorf("ActiveProfile = %q, want %q", cfg.ActiveProfile, "authoring")
+		}
+		if !strings.HasPrefix(cfg.Sources["color_scheme"], "profile authoring") {
+			t.Errorf("Sources[color_scheme] = %q", cfg.Sources["color_scheme"])
+		}
+	})
+
+	t.Run("profile inherits from another profile", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: configFile, Profile: "review-dark"})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "print" || cfg.ColorScheme != ColorSchemeDark {
+			t.Errorf("Theme = %q, ColorScheme = %q", cfg.Theme, cfg.ColorScheme)
+		}
+		if cfg.PrintCSS != filepath.Join(tmpDir, "review.css") {
+			t.Errorf("PrintCSS = %q, want path relative to config file", cfg.PrintCSS)
+		}
+	})
+
+	t.Run("profile is selected from environment", func(t *testing.T) {
+		originalGetenv := getenv
+		defer func() { getenv = originalGetenv }()
+		getenv = func(key string) string {
+			if key == ProfileEnv {
+				return "review"
+			}
+			return ""
+		}
+
+		cfg, err := LoadWithOptions(Options{Path: configFile})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.ActiveProfile != "review" {
+			t.Errorf("ActiveProfile = %q, want %q", cfg.ActiveProfile, "review")
+		}
+	})
+
+	t.Run("unknown profile lists available profiles", func(t *testing.T) {
+		_, err := LoadWithOptions(Options{Path: configFile, Profile: "reveiw"})
+		if err == nil || !strings.Contains(err.Error(), `unknown profile "reveiw" (available: authoring, review, review-dark)`) {
+			t.Errorf("LoadWithOptions() error = %v", err)
+		}
+	})
+}
+
+func TestLoadWithOptions_InvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		wantErr string
+	}{
+		{
+			name:    "unknown key in profile",
+			content: "profiles:\n  review:\n    them: print\n",
+			wantErr: `line 3: unknown key "them" in profile (did you mean "theme"?)`,
+		},
+		{
+			name:    "nested profiles",
+			content: "profiles:\n  review:\n    profiles: {}\n",
+			wantErr: "line 3: profiles cannot be nested",
+		},
+		{
+			name:    "inheritance cycle",
+			content: "profiles:\n  a:\n    inherits: b\n  b:\n    inherits: a\n",
+			profile: "a",
+			wantErr: "inheritance cycle: a -> b -> a",
+		},
+		{
+			name:    "inherits unknown profile",
+			content: "profiles:\n  a:\n    inherits: missing\n",
+			profile: "a",
+			wantErr: `profile "a" inherits unknown profile "missing"`,
+		},
+		{
+			name:    "wrong type in profile",
+			content: "profiles:\n  a:\n    color_scheme_toggle: sometimes\n",
+			profile: "a",
+			wantErr: "line 3: cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, configFile, tt.content)
+
+			_, err := LoadWithOptions(Options{Path: configFile, Profile: tt.profile})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadWithOptions() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NoProjectConfigEnv is the environment variable that disables project
+// config discovery when set to a non-empty value.
+const NoProjectConfigEnv = "MDP_NO_PROJECT_CONFIG"
+
+var projectConfigNames = []string{".mdp.yaml", ".mdp.yml"}
+
+// userOnlyKeys are the keys a project config file cannot set, in its top
+// level or in a profile, because they run commands or decide where and how
+// pages are written.
+var userOnlyKeys = []string{"browser_command", "output_dir", "output_permissions"}
+
+// findProjectConfig searches dir and its parents up to the repository root,
+// the first directory containing .git, for a project config file and
+// returns the nearest one. It returns an empty string if none is found or
+// dir is not in a repository.
+func findProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	root := repoRoot(dir)
+	if root == "" {
+		return ""
+	}
+
+	for {
+		for _, name := range projectConfigNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		if dir == root {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// repoRoot returns the nearest directory from dir upward that contains
+// .git, or an empty string if there is none.
+func repoRoot(dir string) string {
+	for {
+		if isRepoRoot(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// applyProjectFile merges the project config file at path into cfg after
+// checking that it sets no user-only keys.
+func (cfg *Config) applyProjectFile(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is a discovered project config file
+	if err != nil {
+		return err
+	}
+
+	if err := checkProjectConfig(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.apply(data, path, filepath.Dir(path)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// checkProjectConfig reports an error if YAML data sets a user-only key at
+// the top level or in a profile.
+func checkProjectConfig(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	mappings := []*yaml.Node{doc.Content[0]}
+	for i := 0; i < len(doc.Content[0].Content); i += 2 {
+		key, value := doc.Content[0].Content[i], doc.Content[0].Content[i+1]
+		if key.Value != "profiles" || value.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 1; j < len(value.Content); j += 2 {
+			if value.Content[j].Kind == yaml.MappingNode {
+				mappings = append(mappings, value.Content[j])
+			}
+		}
+	}
+
+	for _, mapping := range mappings {
+		for i := 0; i < len(mapping.Content); i += 2 {
+			key := mapping.Content[i]
+			if slices.Contains(userOnlyKeys, key.Value) {
+				return fmt.Errorf("line %d: %s cannot be set in a project config file; set it in the user config file", key.Line, key.Value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	docs := filepath.Join(repo, "docs", "guide")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(docs, 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+
+	t.Run("returns empty string when no project config exists", func(t *testing.T) {
+		writeFile(t, filepath.Join(tmpDir, ".mdp.yaml"), "theme: outside\n")
+		defer func() { _ = os.Remove(filepath.Join(tmpDir, ".mdp.yaml")) }()
+
+		if result := findProjectConfig(docs); result != "" {
+			t.Errorf("findProjectConfig() = %q, want empty string (search must stop at repo root)", result)
+		}
+	})
+
+	t.Run("finds config at repo root", func(t *testing.T) {
+		configFile := filepath.Join(repo, ".mdp.yml")
+		writeFile(t, configFile, "theme: repo\n")
+		defer func() { _ = os.Remove(configFile) }()
+
+		if result := findProjectConfig(docs); result != configFile {
+			t.Errorf("findProjectConfig() = %q, want %q", result, configFile)
+		}
+	})
+
+	t.Run("ignores config files outside a repository", func(t *testing.T) {
+		outside := filepath.Join(tmpDir, "notes")
+		writeFile(t, filepath.Join(outside, ".mdp.yaml"), "theme: outside\n")
+
+		if result := findProjectConfig(outside); result != "" {
+			t.Errorf("findProjectConfig() = %q, want empty string outside a repository", result)
+		}
+	})
+
+	t.Run("nearest config wins", func(t *testing.T) {
+		rootConfig := filepath.Join(repo, ".mdp.yaml")
+		nearConfig := filepath.Join(repo, "docs", ".mdp.yaml")
+		writeFile(t, rootConfig, "theme: repo\n")
+		writeFile(t, nearConfig, "theme: docs\n")
+		defer func() {
+			_ = os.Remove(rootConfig)
+			_ = os.Remove(nearConfig)
+		}()
+
+		if result := findProjectConfig(docs); result != nearConfig {
+			t.Errorf("findProjectConfig() = %q, want %q", result, nearConfig)
+		}
+	})
+}
+
+func TestLoadWithOptions_ProjectConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	userConfig := filepath.Join(tmpDir, "user", "config.yaml")
+	writeFile(t, userConfig, "output_dir: /user/output\ntheme: user-theme\ncolor_scheme: dark\n")
+
+	repo := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	projectConfig := filepath.Join(repo, ".mdp.yaml")
+	writeFile(t, projectConfig, "theme: project-theme\ncolor_scheme: light\nprint_css: styles/print.css\n")
+
+	t.Run("project config overrides user config", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: repo})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.OutputDir != "/user/output" {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/user/output")
+		}
+		if cfg.Theme != "project-theme" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "project-theme")
+		}
+		if cfg.ColorScheme != ColorSchemeLight {
+			t.Errorf("ColorScheme = %q, want %q", cfg.ColorScheme, ColorSchemeLight)
+		}
+		if cfg.ProjectPath != projectConfig {
+			t.Errorf("ProjectPath = %q, want %q", cfg.ProjectPath, projectConfig)
+		}
+		if cfg.ConfigDir != filepath.Dir(userConfig) {
+			t.Errorf("ConfigDir = %q, want user config directory", cfg.ConfigDir)
+		}
+		if cfg.Sources["theme"] != projectConfig || cfg.Sources["output_dir"] != userConfig {
+			t.Errorf("Sources = %v", cfg.Sources)
+		}
+	})
+
+	t.Run("relative paths are resolved against project config directory", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: repo})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		expected := filepath.Join(repo, "styles", "print.css")
+		if cfg.PrintCSS != expected {
+			t.Errorf("PrintCSS = %q, want %q", cfg.PrintCSS, expected)
+		}
+	})
+
+	t.Run("NoProjectConfig disables discovery", func(t *testing.T) {
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: repo, NoProjectConfig: true})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "user-theme" || cfg.ProjectPath != "" {
+			t.Errorf("Theme = %q, ProjectPath = %q, want user config only", cfg.Theme, cfg.ProjectPath)
+		}
+	})
+
+	t.Run("environment variable disables discovery", func(t *testing.T) {
+		originalGetenv := getenv
+		defer func() { getenv = originalGetenv }()
+		getenv = func(key string) string {
+			if key == NoProjectConfigEnv {
+				return "1"
+			}
+			return ""
+		}
+
+		cfg, err := LoadWithOptions(Options{Path: userConfig, Dir: repo})
+		if err != nil {
+			t.Fatalf("LoadWithOptions() returned error: %v", err)
+		}
+		if cfg.Theme != "user-theme" {
+			t.Errorf("Theme = %q, want %q", cfg.Theme, "user-theme")
+		}
+	})
+
+	t.Run("errors in project config name the file", func(t *testing.T) {
+		badRepo := filepath.Join(tmpDir, "bad")
+		if err := os.MkdirAll(filepath.Join(badRepo, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		badConfig := filepath.Join(badRepo, ".mdp.yaml")
+		writeFile(t, badConfig, "color_scheme: sepia\n")
+
+		_, err := LoadWithOptions(Options{Path: userConfig, Dir: badRepo})
+		if err == nil {
+			t.Fatal("LoadWithOptions() should return error")
+		}
+		if want := badConfig + ": invalid color_scheme"; !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("LoadWithOptions() error = %q, want prefix %q", err.Error(), want)
+		}
+	})
+	t.Run("user-only keys are rejected", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			want    string
+		}{
+			{
+				name:    "browser_command",
+				content: "theme: project-theme\nbrowser_command: open-it {path}\n",
+				want:    "line 2: browser_command cannot be set in a project config file",
+			},
+			{
+				name:    "output_dir",
+				content: "output_dir: /tmp/shared\n",
+				want:    "line 1: output_dir cannot be set in a project config file",
+			},
+			{
+				name:    "output_permissions in a profile",
+				content: "profiles:\n  share:\n    output_permissions: shared\n",
+				want:    "line 3: output_permissions cannot be set in a project config file",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := filepath.Join(t.TempDir(), "repo")
+				if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+					t.Fatal(err)
+				}
+				projectConfig := filepath.Join(repo, ".mdp.yaml")
+				writeFile(t, projectConfig, tt.content)
+
+				_, err := LoadWithOptions(Options{Path: userConfig, Dir: repo})
+				if err == nil {
+					t.Fatal("LoadWithOptions() should return error")
+				}
+				if want := projectConfig + ": " + tt.want; !strings.HasPrefix(err.Error(), want) {
+					t.Errorf("LoadWithOptions() error = %q, want prefix %q", err.Error(), want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed starter.yaml
+var starterConfig []byte
+
+// SourceDefault is the source reported for keys left at their defaults.
+const SourceDefault = "default"
+
+// Entry is an effective config value together with where it came from.
+type Entry struct {
+	Key string
+	// Value is the value formatted as YAML.
+	Value  string
+	Source string
+}
+
+// PathCandidates returns the user config file locations in the order they
+// are searched.
+func PathCandidates() []string {
+	return configPathCandidates()
+}
+
+// FindProjectConfig returns the project config file that applies to dir, or
+// an empty string if there is none. It ignores MDP_NO_PROJECT_CONFIG.
+func FindProjectConfig(dir string) string {
+	return findProjectConfig(dir)
+}
+
+// Entries returns the effective value and source of every key that can be
+// set from a single value and of sections such as markdown, in the order
+// they are declared. Sections are formatted in YAML flow style.
+func (cfg *Config) Entries() []Entry {
+	var entries []Entry
+
+	value := reflect.ValueOf(cfg).Elem()
+	typ := value.Type()
+	keys := overridableKeys()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		section := field.Type.Kind() == reflect.Struct && key != "" && key != "-"
+		if !slices.Contains(keys, key) && !section {
+			continue
+		}
+
+		formatted, err := formatValue(value.Field(i).Interface(), section)
+		if err != nil {
+			formatted = []byte(fmt.Sprint(value.Field(i).Interface()))
+		}
+
+		source, ok := cfg.Sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+
+		entries = append(entries, Entry{
+			Key:    key,
+			Value:  strings.TrimSpace(string(formatted)),
+			Source: source,
+		})
+	}
+
+	return entries
+}
+
+// formatValue formats v as YAML, in flow style if flow is set.
+func formatValue(v any, flow bool) ([]byte, error) {
+	if !flow {
+		return yaml.Marshal(v)
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	node.Style = yaml.FlowStyle
+	return yaml.Marshal(&node)
+}
+
+// Init writes a commented starter config file to path, or to the first
+// user config candidate if path is empty, and returns the path written. It
+// refuses to overwrite an existing file.
+func Init(path string) (string, error) {
+	if path == "" {
+		candidates := configPathCandidates()
+		if len(candidates) == 0 {
+			return "", errors.New("cannot determine config directory")
+		}
+		path = candidates[0]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: config is not secret
+		return "", err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644) //nolint:gosec // G302: config is not secret
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("config file already exists: %s", path)
+		}
+		return "", err
+	}
+	if _, err := f.Write(starterConfig); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	writeFile(t, configFile, "theme: custom\nmarkdown:\n  hard_wraps: true\n")
+
+	cfg, err := LoadWithOptions(Options{
+		Path:      configFile,
+		Overrides: []Override{{Key: "color_scheme", Value: "dark", Source: "flag"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() returned error: %v", err)
+	}
+
+	entries := cfg.Entries()
+	want := map[string]Entry{
+		"theme":               {Key: "theme", Value: "custom", Source: configFile},
+		"color_scheme":        {Key: "color_scheme", Value: "dark", Source: "flag"},
+		"color_scheme_toggle": {Key: "color_scheme_toggle", Value: "false", Source: SourceDefault},
+		"print_css":           {Key: "print_css", Value: `""`, Source: SourceDefault},
+		"markdown":            {Key: "markdown", Value: "{preset: gfm, hard_wraps: true}", Source: configFile},
+	}
+
+	if entries[0].Key != "output_dir" {
+		t.Errorf("Entries()[0].Key = %q, want output_dir", entries[0].Key)
+	}
+	found := map[string]bool{}
+	for _, entry := range entries {
+		found[entry.Key] = true
+		if entry.Key == "profiles" {
+			t.Error("Entries() should not include profiles")
+		}
+		if expected, ok := want[entry.Key]; ok && entry != expected {
+			t.Errorf("Entries() entry = %+v, want %+v", entry, expected)
+		}
+	}
+	if !found["markdown"] {
+		t.Error("Entries() should include markdown")
+	}
+}
+
+func TestInit(t *testing.T) {
+	t.Run("writes to first candidate when path is empty", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		originalConfigDir := userConfigDir
+		defer func() { userConfigDir = originalConfigDir }()
+		userConfigDir = func() (string, error) { return tmpDir, nil }
+
+		path, err := Init("")
+		if err != nil {
+			t.Fatalf("Init() returned error: %v", err)
+		}
+		expected := filepath.Join(tmpDir, "mdp", "config.yaml")
+		if path != expected {
+			t.Errorf("Init() = %q, want %q", path, expected)
+		}
+
+		// The starter file must load cleanly
+		if _, err := Load(path); err != nil {
+			t.Errorf("Load() of starter config returned error: %v", err)
+		}
+	})
+
+	t.Run("refuses to overwrite existing file", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		writeFile(t, configFile, "theme: mine\n")
+
+		if _, err := Init(configFile); err == nil {
+			t.Error("Init() should return error when the file exists")
+		}
+		content, _ := os.ReadFile(configFile) //nolint:gosec // G304: path is from test
+		if string(content) != "theme: mine\n" {
+			t.Errorf("Init() modified existing file: %q", content)
+		}
+	})
+}
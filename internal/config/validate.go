@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var lookPath = exec.LookPath
+
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type \S+$`)
+
+// decode strictly decodes YAML data into cfg. Unknown keys and values of the
+// wrong type are reported with their line numbers.
+func decode(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err := dec.Decode(cfg)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	messages := make([]string, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		if m := unknownFieldPattern.FindStringSubmatch(msg); m != nil {
+			msg = fmt.Sprintf("line %s: unknown key %q", m[1], m[2])
+			if suggestion := closestKey(m[2]); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+		}
+		messages = append(messages, msg)
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+// topLevelKeys returns the keys of the top-level mapping in YAML data.
+func topLevelKeys(data []byte) ([]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	mapping := doc.Content[0]
+	keys := make([]string, 0, len(mapping.Content)/2)
+	for i := 0; i < len(mapping.Content); i += 2 {
+		keys = append(keys, mapping.Content[i].Value)
+	}
+	return keys, nil
+}
+
+// knownKeys returns the YAML keys accepted in a config file.
+func knownKeys() []string {
+	var keys []string
+	typ := reflect.TypeFor[Config]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// closestKey returns the known key most similar to key, or an empty string
+// if none is close enough to be a likely typo.
+func closestKey(key string) string {
+	best, bestDistance := "", 3
+	for _, known := range knownKeys() {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Problem describes a configuration value that will not work in the
+// current environment.
+type Problem struct {
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// Check validates cfg against the environment: the browser command must be
+// found on PATH, the output directory must be writable and print_css must be
+// readable. Themes are checked by the caller, since they may be built in.
+func Check(cfg *Config) []Problem {
+	var problems []Problem
+
+	if len(cfg.BrowserCommand) > 0 {
+		if _, err := lookPath(cfg.BrowserCommand[0]); err != nil {
+			problems = append(problems, Problem{
+				Key:     "browser_command",
+				Message: fmt.Sprintf("%q not found in PATH", cfg.BrowserCommand[0]),
+			})
+		}
+	}
+
+	if err := checkWritableDir(cfg.OutputDir); err != nil {
+		problems = append(problems, Problem{
+			Key:     "output_dir",
+			Message: err.Error(),
+		})
+	}
+
+	if cfg.PrintCSS != "" {
+		if _, err := os.Stat(cfg.PrintCSS); err != nil {
+			problems = append(problems, Problem{
+				Key:     "print_css",
+				Message: fmt.Sprintf("cannot read %s: %v", cfg.PrintCSS, errors.Unwrap(err)),
+			})
+		}
+	}
+
+	return problems
+}
+
+// checkWritableDir reports whether dir, or the nearest existing ancestor that
+// it would be created in, accepts new files.
+func checkWritableDir(dir string) error {
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", target)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			return fmt.Errorf("no existing parent directory for %s", dir)
+		}
+		target = parent
+	}
+
+	f, err := os.CreateTemp(target, ".mdp-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable", target)
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_StrictDecoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string
+	}{
+		{
+			name:    "unknown key with suggestion",
+			content: "browser_command: echo\noutptu_dir: /tmp\n",
+			wantErr: []string{"config.yaml", `line 2: unknown key "outptu_dir"`, `did you mean "output_dir"?`},
+		},
+		{
+			name:    "unknown key without suggestion",
+			content: "something_else: 1\n",
+			wantErr: []string{`line 1: unknown key "something_else"`},
+		},
+		{
+			name:    "wrong type",
+			content: "color_scheme_toggle: sometimes\n",
+			wantErr: []string{"line 1: cannot unmarshal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configFile := filepath.Join(tmpDir, "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil { //nolint:gosec // G306: test file
+				t.Fatal(err)
+			}
+
+			_, err := Load(configFile)
+			if err == nil {
+				t.Fatal("Load() should return error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want to contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+
+	t.Run("empty file is accepted", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(configFile, []byte(""), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(configFile)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if cfg.Path != configFile {
+			t.Errorf("Path = %q, want %q", cfg.Path, configFile)
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	lookPath = func(file string) (string, error) {
+		if file == "found" {
+			return "/usr/bin/found", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	t.Run("valid config has no problems", func(t *testing.T) {
+		cfg := &Config{
+			OutputDir:      filepath.Join(t.TempDir(), "not", "yet", "created"),
+			BrowserCommand: Command{"found", "--new-window"},
+		}
+
+		if problems := Check(cfg); len(problems) != 0 {
+			t.Errorf("Check() = %v, want no problems", problems)
+		}
+	})
+
+	t.Run("reports missing browser, unusable output dir and print css", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		notDir := filepath.Join(tmpDir, "file")
+		if err := os.WriteFile(notDir, []byte(""), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		cfg := &Config{
+			OutputDir:      notDir,
+			BrowserCommand: Command{"missing"},
+			PrintCSS:       filepath.Join(tmpDir, "missing.css"),
+		}
+
+		problems := Check(cfg)
+		if len(problems) != 3 {
+			t.Fatalf("Check() = %v, want 3 problems", problems)
+		}
+		wantKeys := []string{"browser_command", "output_dir", "print_css"}
+		for i, key := range wantKeys {
+			if problems[i].Key != key {
+				t.Errorf("Check()[%d].Key = %q, want %q", i, problems[i].Key, key)
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrNotRunning is returned when no daemon listens on the control socket.
+var ErrNotRunning = errors.New("daemon is not running")
+
+const spawnPollInterval = 50 * time.Millisecond
+
+// Client talks to the daemon serving an output directory.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a client for the daemon serving outputDir. It does not
+// connect until a request is made.
+func NewClient(outputDir string) *Client {
+	socketPath := SocketPath(outputDir)
+	return &Client{
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// Open asks the daemon to show a Markdown file.
+func (c *Client) Open(req Request) (*Response, error) {
+	var resp Response
+	if err := c.do(http.MethodPost, "/open", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Status returns the state of the daemon.
+func (c *Client) Status() (*Status, error) {
+	var status Status
+	if err := c.do(http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Stop asks the daemon to exit.
+func (c *Client) Stop() error {
+	return c.do(http.MethodPost, "/stop", nil, nil)
+}
+
+func (c *Client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	// The host is ignored; requests always go to the socket.
+	req, err := http.NewRequest(method, "http://mdp"+path, reader)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+			return fmt.Errorf("daemon returned %s", resp.Status)
+		}
+		return errors.New(errResp.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Spawn starts command, which should run a daemon for outputDir in the
+// foreground, detached from the terminal with its output appended to the
+// daemon log. It waits until the daemon accepts requests.
+func Spawn(command []string, outputDir string, timeout time.Duration) (*Status, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil { //nolint:gosec // G301: output directory needs to be readable
+		return nil, err
+	}
+	logPath := LogPath(outputDir)
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600) //nolint:gosec // G304: log file in the output directory
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = logFile.Close() }()
+	logStart, _ := logFile.Seek(0, io.SeekEnd)
+
+	cmd := exec.Command(command[0], command[1:]...) //nolint:gosec // G204: command is mdp itself
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	client := NewClient(outputDir)
+	deadline := time.Now().Add(timeout)
+	for {
+		if status, err := client.Status(); err == nil {
+			return status, nil
+		}
+
+		select {
+		case err := <-exited:
+			if output := readLog(logPath, logStart); output != "" {
+				return nil, fmt.Errorf("daemon exited: %s", output)
+			}
+			return nil, fmt.Errorf("daemon exited: %w", err)
+		case <-time.After(spawnPollInterval):
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("daemon did not start within %s; see %s", timeout, logPath)
+		}
+	}
+}
+
+// readLog returns what was appended to the log since offset.
+func readLog(path string, offset int64) string {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: log file in the output directory
+	if err != nil || int64(len(data)) < offset {
+		return ""
+	}
+	return strings.TrimSpace(string(data[offset:]))
+}
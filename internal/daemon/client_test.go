@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_NotRunning(t *testing.T) {
+	t.Run("without socket", func(t *testing.T) {
+		client := NewClient(shortTempDir(t))
+		if _, err := client.Status(); !errors.Is(err, ErrNotRunning) {
+			t.Errorf("Status() error = %v, want ErrNotRunning", err)
+		}
+	})
+
+	t.Run("with stale socket", func(t *testing.T) {
+		outputDir := shortTempDir(t)
+		if err := os.WriteFile(SocketPath(outputDir), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		client := NewClient(outputDir)
+		if _, err := client.Open(Request{Source: "/doc.md"}); !errors.Is(err, ErrNotRunning) {
+			t.Errorf("Open() error = %v, want ErrNotRunning", err)
+		}
+	})
+}
+
+func TestSpawn(t *testing.T) {
+	t.Run("reports early exit with log output", func(t *testing.T) {
+		outputDir := shortTempDir(t)
+
+		_, err := Spawn([]string{"sh", "-c", "echo 'bad config' >&2; exit 1"}, outputDir, 5*time.Second)
+		if err == nil || !strings.Contains(err.Error(), "bad config") {
+			t.Errorf("Spawn() error = %v, want log output", err)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, logName)); err != nil {
+			t.Errorf("log file not created: %v", err)
+		}
+	})
+
+	t.Run("times out when daemon does not listen", func(t *testing.T) {
+		outputDir := shortTempDir(t)
+
+		_, err := Spawn([]string{"sleep", "1"}, outputDir, 200*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "did not start") {
+			t.Errorf("Spawn() error = %v, want timeout", err)
+		}
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package daemon
+
+import "os/exec"
+
+// detach does nothing where processes have no sessions.
+func detach(*exec.Cmd) {}
@@ -0,0 +1,586 @@
+// Package daemon implements the optional preview daemon. A single daemon
+// serves the generated pages of an output directory over HTTP, keeps a live
+// connection to every open page and accepts requests from later mdp
+// invocations on a Unix socket in the output directory, so that a page that
+// is already open is reloaded instead of opened in another tab.
+package daemon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/masawada/mdp/internal/browser"
+	"github.com/masawada/mdp/internal/watcher"
+)
+
+const (
+	socketName = "daemon.sock"
+	logName    = "daemon.log"
+	eventsPath = "_mdp/events"
+
+	heartbeatInterval = 30 * time.Second
+	shutdownTimeout   = 2 * time.Second
+)
+
+// Actions reported in a Response.
+const (
+	// ActionOpened means the page was opened in a new browser tab.
+	ActionOpened = "opened"
+	// ActionReloaded means an open page was told to reload.
+	ActionReloaded = "reloaded"
+	// ActionGenerated means the page was generated but no browser is
+	// available to open it.
+	ActionGenerated = "generated"
+)
+
+// reloadScript is added to every served page. It keeps a live connection
+// to the daemon and reloads the page when asked.
+const reloadScript = `<script>
+(function () {
+  var events = new EventSource(%q + "?page=" + encodeURIComponent(location.pathname));
+  events.addEventListener("reload", function () {
+    location.reload();
+  });
+})();
+</script>
+`
+
+// SocketPath returns the control socket of the daemon serving outputDir.
+func SocketPath(outputDir string) string {
+	return filepath.Join(outputDir, socketName)
+}
+
+// LogPath returns the log file of a daemon started with Spawn.
+func LogPath(outputDir string) string {
+	return filepath.Join(outputDir, logName)
+}
+
+// Request asks the daemon to show a Markdown file.
+type Request struct {
+	// Source is the absolute path of the Markdown file.
+	Source string `json:"source"`
+	// Watch asks the daemon to regenerate the page when Source changes.
+	Watch bool `json:"watch,omitempty"`
+	// Options are passed to the RenderFunc unchanged.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// Response describes how the daemon handled a Request.
+type Response struct {
+	Action string `json:"action"`
+	// Path is the generated HTML file.
+	Path string `json:"path"`
+	// URL is the address the daemon serves the page at.
+	URL string `json:"url"`
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID       int          `json:"pid"`
+	URL       string       `json:"url"`
+	OutputDir string       `json:"output_dir"`
+	StartedAt time.Time    `json:"started_at"`
+	Pages     []PageStatus `json:"pages"`
+	Watching  []string     `json:"watching"`
+}
+
+// PageStatus describes a page with live connections.
+type PageStatus struct {
+	URL     string `json:"url"`
+	Viewers int    `json:"viewers"`
+}
+
+// Page is a generated HTML page.
+type Page struct {
+	Path  string
+	Title string
+	// Unchanged reports that rendering produced the content the page
+	// already had, so open viewers need not reload.
+	Unchanged bool
+}
+
+// RenderFunc generates the page for a Markdown file.
+type RenderFunc func(source string, options json.RawMessage) (Page, error)
+
+// OpenFunc opens a page in the browser.
+type OpenFunc func(target browser.Target) error
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithOpener sets the function used to open pages that are not open yet.
+// Without it, pages are only generated.
+func WithOpener(open OpenFunc) Option {
+	return func(s *Server) {
+		s.open = open
+	}
+}
+
+// WithLogger sets where the daemon logs errors. Logs are discarded by
+// default.
+func WithLogger(w io.Writer) Option {
+	return func(s *Server) {
+		s.logger = log.New(w, "", log.LstdFlags)
+	}
+}
+
+// Server is the preview daemon.
+type Server struct {
+	outputDir string
+	render    RenderFunc
+	open      OpenFunc
+	logger    *log.Logger
+	token     string
+
+	pageServer    *http.Server
+	controlServer *http.Server
+	pageAddr      string
+	startedAt     time.Time
+
+	mu      sync.Mutex
+	viewers map[string]map[chan string]struct{}
+	watches map[string]*watch
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type watch struct {
+	watcher *watcher.Watcher
+	options json.RawMessage
+}
+
+// NewServer creates a daemon for outputDir that generates pages with render.
+func NewServer(outputDir string, render RenderFunc, opts ...Option) (*Server, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		outputDir: outputDir,
+		render:    render,
+		logger:    log.New(io.Discard, "", 0),
+		token:     hex.EncodeToString(token),
+		viewers:   make(map[string]map[chan string]struct{}),
+		watches:   make(map[string]*watch),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Start listens on the control socket and on a random localhost port and
+// serves requests in the background until Stop is called.
+func (s *Server) Start() error {
+	if err := os.MkdirAll(s.outputDir, 0755); err != nil { //nolint:gosec // G301: output directory needs to be readable
+		return err
+	}
+
+	socketPath := SocketPath(s.outputDir)
+	if _, err := NewClient(s.outputDir).Status(); err == nil {
+		return fmt.Errorf("daemon is already running on %s", socketPath)
+	}
+	// The socket is left behind by a daemon that did not stop cleanly.
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	controlListener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		_ = controlListener.Close()
+		return err
+	}
+
+	pageListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = controlListener.Close()
+		return err
+	}
+
+	s.pageAddr = pageListener.Addr().String()
+	s.startedAt = time.Now()
+	s.pageServer = &http.Server{Handler: http.HandlerFunc(s.servePage), ReadHeaderTimeout: 10 * time.Second}
+	s.controlServer = &http.Server{Handler: s.controlHandler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go s.serve(s.pageServer, pageListener)
+	go s.serve(s.controlServer, controlListener)
+
+	return nil
+}
+
+func (s *Server) serve(server *http.Server, listener net.Listener) {
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Printf("server error: %v", err)
+		s.Stop()
+	}
+}
+
+// URL returns the base URL pages are served under.
+func (s *Server) URL() string {
+	return s.pageURL("/" + s.token + "/")
+}
+
+// Done returns a channel that is closed when the daemon stops.
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stop closes all connections, stops watching files and removes the
+// control socket.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if s.pageServer != nil {
+			_ = s.pageServer.Shutdown(ctx)
+		}
+		if s.controlServer != nil {
+			_ = s.controlServer.Shutdown(ctx)
+		}
+
+		s.mu.Lock()
+		for source, w := range s.watches {
+			_ = w.watcher.Close()
+			delete(s.watches, source)
+		}
+		s.mu.Unlock()
+
+		_ = os.Remove(SocketPath(s.outputDir))
+	})
+}
+
+func (s *Server) controlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /open", s.handleOpen)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("POST /stop", s.handleStop)
+	return mux
+}
+
+func (s *Server) handleOpen(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	if !filepath.IsAbs(req.Source) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("source must be an absolute path: %s", req.Source))
+		return
+	}
+
+	resp, err := s.show(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// show generates the page for req and reloads it where it is open, or
+// opens it otherwise.
+func (s *Server) show(req Request) (*Response, error) {
+	page, err := s.render(req.Source, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	pagePath, err := s.pagePath(page.Path)
+	if err != nil {
+		return nil, err
+	}
+	pageURL := s.pageURL(pagePath)
+
+	if req.Watch {
+		if err := s.watch(req.Source, req.Options); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &Response{Path: page.Path, URL: pageURL}
+	switch {
+	case s.notify(pageKey(pagePath), "reload") > 0:
+		resp.Action = ActionReloaded
+	case s.open == nil:
+		resp.Action = ActionGenerated
+	default:
+		if err := s.open(browser.Target{Path: page.Path, URL: pageURL, Title: page.Title}); err != nil {
+			return nil, fmt.Errorf("failed to open browser: %w", err)
+		}
+		resp.Action = ActionOpened
+	}
+	return resp, nil
+}
+
+// pagePath returns the unescaped URL path a generated file is served at.
+func (s *Server) pagePath(filePath string) (string, error) {
+	rel, err := filepath.Rel(s.outputDir, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the daemon's output directory %s", filePath, s.outputDir)
+	}
+	return "/" + s.token + "/" + filepath.ToSlash(rel), nil
+}
+
+// pageURL returns the address of the page at pagePath.
+func (s *Server) pageURL(pagePath string) string {
+	u := url.URL{Scheme: "http", Host: s.pageAddr, Path: pagePath}
+	return u.String()
+}
+
+// pageKey identifies a page regardless of whether its path names
+// index.html.
+func pageKey(pagePath string) string {
+	return strings.TrimSuffix(pagePath, "index.html")
+}
+
+// watch regenerates the page for source whenever it changes and reloads it
+// in every browser it is open in.
+func (s *Server) watch(source string, options json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w, ok := s.watches[source]; ok {
+		w.options = options
+		return nil
+	}
+
+	fileWatcher, err := watcher.New(source)
+	if err != nil {
+		return err
+	}
+	w := &watch{watcher: fileWatcher, options: options}
+	s.watches[source] = w
+	fileWatcher.Start()
+
+	go func() {
+		for {
+			select {
+			case <-fileWatcher.Events():
+				s.mu.Lock()
+				options := w.options
+				s.mu.Unlock()
+
+				page, err := s.render(source, options)
+				if err != nil {
+					s.logger.Printf("%s: %v", source, err)
+					continue
+				}
+				if page.Unchanged {
+					continue
+				}
+				pagePath, err := s.pagePath(page.Path)
+				if err != nil {
+					s.logger.Printf("%s: %v", source, err)
+					continue
+				}
+				s.notify(pageKey(pagePath), "reload")
+			case err := <-fileWatcher.Errors():
+				s.logger.Printf("%s: watcher error: %v", source, err)
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// notify sends event to every live connection of a page and returns how
+// many there were.
+func (s *Server) notify(key, event string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.viewers[key] {
+		select {
+		case ch <- event:
+		default:
+			// The viewer has an event pending already.
+		}
+	}
+	return len(s.viewers[key])
+}
+
+func (s *Server) subscribe(key string) chan string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan string, 1)
+	if s.viewers[key] == nil {
+		s.viewers[key] = make(map[chan string]struct{})
+	}
+	s.viewers[key][ch] = struct{}{}
+	return ch
+}
+
+func (s *Server) unsubscribe(key string, ch chan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.viewers[key], ch)
+	if len(s.viewers[key]) == 0 {
+		delete(s.viewers, key)
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	status := Status{
+		PID:       os.Getpid(),
+		URL:       s.URL(),
+		OutputDir: s.outputDir,
+		StartedAt: s.startedAt,
+		Pages:     []PageStatus{},
+		Watching:  []string{},
+	}
+	for key, viewers := range s.viewers {
+		status.Pages = append(status.Pages, PageStatus{URL: s.pageURL(key), Viewers: len(viewers)})
+	}
+	for source := range s.watches {
+		status.Watching = append(status.Watching, source)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(status.Pages, func(a, b PageStatus) int { return strings.Compare(a.URL, b.URL) })
+	slices.Sort(status.Watching)
+	writeJSON(w, status)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, struct{}{})
+	go s.Stop()
+}
+
+// servePage serves generated pages and their live connections. Requests
+// must name the daemon's token and address it by IP, which keeps other
+// local users and DNS rebinding attacks out.
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.Host != s.pageAddr {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	prefix := "/" + s.token + "/"
+	rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if rest == eventsPath {
+		s.serveEvents(w, r)
+		return
+	}
+
+	name := filepath.Join(s.outputDir, filepath.FromSlash(path.Clean("/"+rest)))
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		name = filepath.Join(name, "index.html")
+	}
+	if filepath.Ext(name) != ".html" {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := os.ReadFile(name) //nolint:gosec // G304: path is confined to the output directory
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(injectReloadScript(content, prefix+eventsPath))
+}
+
+// injectReloadScript adds the reload script before the closing body tag,
+// or at the end of a page without one.
+func injectReloadScript(content []byte, events string) []byte {
+	script := fmt.Sprintf(reloadScript, events)
+	html := string(content)
+	if i := strings.LastIndex(strings.ToLower(html), "</body>"); i >= 0 {
+		return []byte(html[:i] + script + html[i:])
+	}
+	return []byte(html + script)
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// The page reports its location.pathname, which the browser escaped.
+	pagePath, err := url.PathUnescape(r.URL.Query().Get("page"))
+	if err != nil {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+	key := pageKey(pagePath)
+	ch := s.subscribe(key)
+	defer s.unsubscribe(key, ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = io.WriteString(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-ch:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: \n\n", event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			// Writing fails once the tab is closed, which drops the viewer.
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
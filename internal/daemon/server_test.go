@@ -0,0 +1,414 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masawada/mdp/internal/browser"
+)
+
+// shortTempDir returns a temporary directory with a path short enough for
+// a Unix socket, which t.TempDir does not guarantee.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "mdpd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+// fakeRender writes a page named after the source file into outputDir.
+func fakeRender(outputDir string) RenderFunc {
+	return func(source string, _ json.RawMessage) (Page, error) {
+		markdown, err := os.ReadFile(source) //nolint:gosec // G304: test file
+		if err != nil {
+			return Page{}, err
+		}
+		name := strings.TrimSuffix(filepath.Base(source), ".md")
+		path := filepath.Join(outputDir, name, "index.html")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: test directory
+			return Page{}, err
+		}
+		html := "<html><body>" + string(markdown) + "</body></html>"
+		if err := os.WriteFile(path, []byte(html), 0644); err != nil { //nolint:gosec // G306: test file
+			return Page{}, err
+		}
+		return Page{Path: path, Title: name}, nil
+	}
+}
+
+type recordingOpener struct {
+	mu      sync.Mutex
+	targets []browser.Target
+}
+
+func (o *recordingOpener) open(target browser.Target) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.targets = append(o.targets, target)
+	return nil
+}
+
+func (o *recordingOpener) count() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.targets)
+}
+
+func startServer(t *testing.T, opts ...Option) (*Server, string, string) {
+	t.Helper()
+	outputDir := shortTempDir(t)
+	sourceDir := t.TempDir()
+
+	s, err := NewServer(outputDir, fakeRender(outputDir), opts...)
+	if err != nil {
+		t.Fatalf("NewServer() returned error: %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	t.Cleanup(s.Stop)
+	return s, outputDir, sourceDir
+}
+
+func writeSource(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	return path
+}
+
+// connectViewer opens a live connection for the page at pageURL, like the
+// injected script does, and returns the events it receives.
+func connectViewer(t *testing.T, s *Server, pageURL string) <-chan string {
+	t.Helper()
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventsURL := s.URL() + eventsPath + "?page=" + url.QueryEscape(u.EscapedPath())
+
+	resp, err := http.Get(eventsURL) //nolint:gosec,noctx // G107: local test server
+	if err != nil {
+		t.Fatalf("GET %s: %v", eventsURL, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	events := make(chan string, 10)
+	connected := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == ": connected" {
+				close(connected)
+			}
+			if event, ok := strings.CutPrefix(line, "event: "); ok {
+				events <- event
+			}
+		}
+	}()
+
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("viewer did not connect")
+	}
+	return events
+}
+
+func TestServer_Open(t *testing.T) {
+	t.Run("opens a page that is not open yet", func(t *testing.T) {
+		opener := &recordingOpener{}
+		s, outputDir, sourceDir := startServer(t, WithOpener(opener.open))
+		source := writeSource(t, sourceDir, "my notes.md", "Hello")
+
+		resp, err := NewClient(outputDir).Open(Request{Source: source})
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		if resp.Action != ActionOpened {
+			t.Errorf("Action = %q, want %q", resp.Action, ActionOpened)
+		}
+		if !strings.HasPrefix(resp.URL, s.URL()) || !strings.HasSuffix(resp.URL, "/my%20notes/index.html") {
+			t.Errorf("URL = %q, want escaped page URL under %s", resp.URL, s.URL())
+		}
+		if opener.count() != 1 || opener.targets[0].URL != resp.URL || opener.targets[0].Title != "my notes" {
+			t.Errorf("opener targets = %+v, want one target for %s", opener.targets, resp.URL)
+		}
+	})
+
+	t.Run("browser command without placeholders gets the page URL", func(t *testing.T) {
+		opener := &recordingOpener{}
+		_, outputDir, sourceDir := startServer(t, WithOpener(opener.open))
+		source := writeSource(t, sourceDir, "notes.md", "Hello")
+
+		resp, err := NewClient(outputDir).Open(Request{Source: source})
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		if opener.count() != 1 {
+			t.Fatalf("opener called %d times, want 1", opener.count())
+		}
+		args := browser.NewOpener([]string{"firefox"}).Args(opener.targets[0])
+		if want := []string{"firefox", resp.URL}; !slices.Equal(args, want) {
+			t.Errorf("Args() = %q, want %q", args, want)
+		}
+	})
+
+	t.Run("reloads a page that is open", func(t *testing.T) {
+		opener := &recordingOpener{}
+		s, outputDir, sourceDir := startServer(t, WithOpener(opener.open))
+		source := writeSource(t, sourceDir, "my notes.md", "Hello")
+		client := NewClient(outputDir)
+
+		first, err := client.Open(Request{Source: source})
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		events := connectViewer(t, s, first.URL)
+
+		second, err := client.Open(Request{Source: source})
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		if second.Action != ActionReloaded {
+			t.Errorf("Action = %q, want %q", second.Action, ActionReloaded)
+		}
+		if opener.count() != 1 {
+			t.Errorf("opener called %d times, want 1", opener.count())
+		}
+		select {
+		case event := <-events:
+			if event != "reload" {
+				t.Errorf("event = %q, want reload", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("viewer did not receive reload event")
+		}
+	})
+
+	t.Run("only generates without opener", func(t *testing.T) {
+		_, outputDir, sourceDir := startServer(t)
+		source := writeSource(t, sourceDir, "doc.md", "Hello")
+
+		resp, err := NewClient(outputDir).Open(Request{Source: source})
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		if resp.Action != ActionGenerated {
+			t.Errorf("Action = %q, want %q", resp.Action, ActionGenerated)
+		}
+	})
+
+	t.Run("reports render errors", func(t *testing.T) {
+		_, outputDir, sourceDir := startServer(t)
+
+		_, err := NewClient(outputDir).Open(Request{Source: filepath.Join(sourceDir, "missing.md")})
+		if err == nil || !strings.Contains(err.Error(), "missing.md") {
+			t.Errorf("Open() error = %v, want render error", err)
+		}
+	})
+
+	t.Run("rejects relative source", func(t *testing.T) {
+		_, outputDir, _ := startServer(t)
+
+		_, err := NewClient(outputDir).Open(Request{Source: "doc.md"})
+		if err == nil || !strings.Contains(err.Error(), "absolute path") {
+			t.Errorf("Open() error = %v, want absolute path error", err)
+		}
+	})
+}
+
+func TestServer_Watch(t *testing.T) {
+	s, outputDir, sourceDir := startServer(t)
+	source := writeSource(t, sourceDir, "doc.md", "Hello")
+	client := NewClient(outputDir)
+
+	resp, err := client.Open(Request{Source: source, Watch: true})
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	events := connectViewer(t, s, resp.URL)
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() returned error: %v", err)
+	}
+	if len(status.Watching) != 1 || status.Watching[0] != source {
+		t.Errorf("Watching = %v, want [%s]", status.Watching, source)
+	}
+
+	writeSource(t, sourceDir, "doc.md", "Changed")
+
+	select {
+	case event := <-events:
+		if event != "reload" {
+			t.Errorf("event = %q, want reload", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("viewer did not receive reload event after change")
+	}
+
+	content, err := os.ReadFile(resp.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Changed") {
+		t.Errorf("page = %q, want regenerated content", content)
+	}
+}
+
+func TestServer_ServePage(t *testing.T) {
+	s, outputDir, sourceDir := startServer(t)
+	source := writeSource(t, sourceDir, "doc.md", "Hello")
+
+	resp, err := NewClient(outputDir).Open(Request{Source: source})
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+
+	t.Run("injects reload script", func(t *testing.T) {
+		body, status := get(t, resp.URL, "")
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+		if !strings.Contains(body, "Hello<script>") || !strings.Contains(body, "EventSource") {
+			t.Errorf("body = %q, want reload script before </body>", body)
+		}
+	})
+
+	t.Run("serves index.html for directories", func(t *testing.T) {
+		_, status := get(t, strings.TrimSuffix(resp.URL, "index.html"), "")
+		if status != http.StatusOK {
+			t.Errorf("status = %d, want 200", status)
+		}
+	})
+
+	t.Run("requires token", func(t *testing.T) {
+		_, status := get(t, strings.Replace(resp.URL, s.token, "wrong", 1), "")
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", status)
+		}
+	})
+
+	t.Run("rejects other hosts", func(t *testing.T) {
+		_, status := get(t, resp.URL, "attacker.example")
+		if status != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", status)
+		}
+	})
+
+	t.Run("does not serve the control socket", func(t *testing.T) {
+		_, status := get(t, s.URL()+socketName, "")
+		if status != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", status)
+		}
+	})
+}
+
+func get(t *testing.T, rawURL, host string) (string, int) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil) //nolint:noctx // test request
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "" {
+		req.Host = host
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), resp.StatusCode
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	t.Run("refuses to start twice", func(t *testing.T) {
+		_, outputDir, _ := startServer(t)
+
+		second, err := NewServer(outputDir, fakeRender(outputDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := second.Start(); err == nil || !strings.Contains(err.Error(), "already running") {
+			t.Errorf("Start() error = %v, want already running", err)
+		}
+	})
+
+	t.Run("replaces stale socket", func(t *testing.T) {
+		outputDir := shortTempDir(t)
+		if err := os.WriteFile(SocketPath(outputDir), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := NewServer(outputDir, fakeRender(outputDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Start(); err != nil {
+			t.Fatalf("Start() returned error: %v", err)
+		}
+		s.Stop()
+	})
+
+	t.Run("stop removes socket", func(t *testing.T) {
+		s, outputDir, _ := startServer(t)
+		client := NewClient(outputDir)
+
+		if err := client.Stop(); err != nil {
+			t.Fatalf("Stop() returned error: %v", err)
+		}
+		select {
+		case <-s.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("daemon did not stop")
+		}
+		if _, err := os.Stat(SocketPath(outputDir)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("socket still exists: %v", err)
+		}
+		if _, err := client.Status(); !errors.Is(err, ErrNotRunning) {
+			t.Errorf("Status() error = %v, want ErrNotRunning", err)
+		}
+	})
+}
+
+func TestInjectReloadScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "before closing body", content: "<html><body><p>x</p></BODY></html>", want: "<p>x</p><script>"},
+		{name: "appended to fragment", content: "<p>x</p>", want: "<p>x</p><script>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(injectReloadScript([]byte(tt.content), "/token/_mdp/events"))
+			if !strings.Contains(got, tt.want) || !strings.Contains(got, `"/token/_mdp/events"`) {
+				t.Errorf("injectReloadScript() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
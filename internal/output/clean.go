@@ -0,0 +1,101 @@
+package output
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Remove deletes the generated page at path and its manifest entry while
+// holding the output directory lock, and then every parent directory up to
+// baseDir that is left empty.
+func Remove(baseDir, path string) error {
+	key, err := Key(baseDir, path)
+	if err != nil {
+		return err
+	}
+	err = UpdateManifest(baseDir, func(m *Manifest) error {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		delete(m.Entries, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return removeEmptyParents(baseDir, path)
+}
+
+// RemoveSource deletes every page generated from srcPath and their manifest
+// entries while holding the output directory lock, so that a document that
+// became private does not keep a shared copy. It returns the pages removed.
+func RemoveSource(baseDir, srcPath string) ([]string, error) {
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	// Most documents have never been shared, so avoid taking the lock and
+	// rewriting the manifest for them.
+	if !hasSource(m, srcPath) {
+		return nil, nil
+	}
+
+	var removed []string
+	err = UpdateManifest(baseDir, func(m *Manifest) error {
+		for _, key := range slices.Sorted(maps.Keys(m.Entries)) {
+			if m.Entries[key].Source != srcPath {
+				continue
+			}
+			path := filepath.Join(baseDir, filepath.FromSlash(key))
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			delete(m.Entries, key)
+			removed = append(removed, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range removed {
+		if err := removeEmptyParents(baseDir, path); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// hasSource reports whether m has an entry for a page of srcPath.
+func hasSource(m *Manifest, srcPath string) bool {
+	for _, entry := range m.Entries {
+		if entry.Source == srcPath {
+			return true
+		}
+	}
+	return false
+}
+
+// removeEmptyParents removes every parent directory of path up to baseDir
+// that is empty.
+func removeEmptyParents(baseDir, path string) error {
+	base := filepath.Clean(baseDir)
+	for dir := filepath.Dir(path); strings.HasPrefix(dir, base+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return nil
+		}
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
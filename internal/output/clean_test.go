@@ -0,0 +1,131 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	baseDir := t.TempDir()
+	w := NewWriter(baseDir)
+
+	removed, err := w.Write("/docs/a/b/old.md", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := w.Write("/docs/a/keep.md", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Remove(baseDir, removed); err != nil {
+		t.Fatalf("Remove() returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(baseDir, "docs", "a", "b")); !os.IsNotExist(err) {
+		t.Errorf("empty parent directory was not removed: %v", err)
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("other preview was removed: %v", err)
+	}
+	if _, err := os.Stat(baseDir); err != nil {
+		t.Errorf("base directory was removed: %v", err)
+	}
+
+	if err := Remove(baseDir, kept); err != nil {
+		t.Fatalf("Remove() returned error: %v", err)
+	}
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		t.Fatalf("base directory was removed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != ManifestName && entry.Name() != lockName {
+			t.Errorf("base directory still contains %s", entry.Name())
+		}
+	}
+
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != 0 {
+		t.Errorf("manifest has %d entries, want 0", len(m.Entries))
+	}
+}
+
+func TestRemove_KeepsEntryOnError(t *testing.T) {
+	baseDir := t.TempDir()
+	path, err := NewWriter(baseDir).Write("/docs/gone.md", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Remove(baseDir, path); !os.IsNotExist(err) {
+		t.Fatalf("Remove() error = %v, want not-exist error", err)
+	}
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != 1 {
+		t.Errorf("manifest has %d entries, want 1", len(m.Entries))
+	}
+}
+
+func TestRemoveSource(t *testing.T) {
+	baseDir := t.TempDir()
+	src := "/docs/a/secret.md"
+
+	mirrored, err := NewWriter(baseDir).Write(src, []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, err := NewWriter(baseDir, WithLayout(LayoutFlat)).Write(src, []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := NewWriter(baseDir).Write("/docs/a/public.md", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := RemoveSource(baseDir, src)
+	if err != nil {
+		t.Fatalf("RemoveSource() returned error: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("RemoveSource() = %v, want the pages of both layouts", removed)
+	}
+	for _, path := range []string{mirrored, filepath.Dir(flat)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("other preview was removed: %v", err)
+	}
+
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasSource(m, src) || len(m.Entries) != 1 {
+		t.Errorf("manifest entries = %v, want only the other preview", m.Entries)
+	}
+
+	t.Run("nothing to remove", func(t *testing.T) {
+		missingDir := filepath.Join(t.TempDir(), "output")
+		removed, err := RemoveSource(missingDir, src)
+		if err != nil || removed != nil {
+			t.Errorf("RemoveSource() = %v, %v, want nothing", removed, err)
+		}
+		if _, err := os.Stat(missingDir); !os.IsNotExist(err) {
+			t.Errorf("output directory was created: %v", err)
+		}
+	})
+}
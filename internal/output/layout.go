@@ -0,0 +1,65 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Layout selects where the page of a source file is placed in the output
+// directory.
+type Layout string
+
+// Layouts accepted by WithLayout.
+const (
+	// LayoutMirror mirrors the absolute path of the source without its
+	// extension, such as Users/you/notes/todo/index.html.
+	LayoutMirror Layout = "mirror"
+	// LayoutFlat places each page in a directory named after the source
+	// and a hash of its path, such as todo-1a2b3c4d5e6f/index.html.
+	LayoutFlat Layout = "flat"
+	// LayoutProject mirrors the path of the source relative to the root of
+	// its repository, under the name of the repository, such as
+	// notes/todo/index.html. Sources outside a repository are mirrored.
+	LayoutProject Layout = "project"
+)
+
+// pageDir returns the directory of the page for srcPath, relative to the
+// output directory.
+func (l Layout) pageDir(srcPath string) string {
+	withoutExt := strings.TrimSuffix(srcPath, filepath.Ext(srcPath))
+	switch l {
+	case LayoutFlat:
+		return filepath.Base(withoutExt) + "-" + pathHash(srcPath)
+	case LayoutProject:
+		if root := repoRoot(filepath.Dir(srcPath)); root != "" {
+			if rel, err := filepath.Rel(root, withoutExt); err == nil {
+				return filepath.Join(filepath.Base(root), rel)
+			}
+		}
+	}
+	return strings.TrimPrefix(withoutExt, "/")
+}
+
+// pathHash returns a short hash identifying path.
+func pathHash(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:6])
+}
+
+// repoRoot returns the nearest directory at or above dir that contains
+// .git, or an empty string if there is none.
+func repoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayout_PageDir(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+
+	tests := []struct {
+		name    string
+		layout  Layout
+		srcPath string
+		want    string
+	}{
+		{
+			name:    "mirror",
+			layout:  LayoutMirror,
+			srcPath: "/Users/user/docs/readme.md",
+			want:    "Users/user/docs/readme",
+		},
+		{
+			name:    "default is mirror",
+			layout:  "",
+			srcPath: "/Users/user/docs/readme.md",
+			want:    "Users/user/docs/readme",
+		},
+		{
+			name:    "flat",
+			layout:  LayoutFlat,
+			srcPath: "/Users/user/docs/readme.md",
+			want:    "readme-" + pathHash("/Users/user/docs/readme.md"),
+		},
+		{
+			name:    "project",
+			layout:  LayoutProject,
+			srcPath: filepath.Join(repo, "docs", "guide.md"),
+			want:    filepath.Join("project", "docs", "guide"),
+		},
+		{
+			name:    "project outside a repository",
+			layout:  LayoutProject,
+			srcPath: filepath.Join(outside, "guide.md"),
+			want:    strings.TrimPrefix(filepath.Join(outside, "guide"), "/"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.layout.pageDir(tt.srcPath); got != tt.want {
+				t.Errorf("pageDir(%q) = %q, want %q", tt.srcPath, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("flat directories differ by path", func(t *testing.T) {
+		a := LayoutFlat.pageDir("/a/readme.md")
+		b := LayoutFlat.pageDir("/b/readme.md")
+		if a == b {
+			t.Errorf("pageDir() = %q for both sources", a)
+		}
+	})
+}
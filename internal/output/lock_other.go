@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package output
+
+import "os"
+
+// Without flock, concurrent writers are not serialized.
+
+func lockFile(*os.File) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// ManifestName is the manifest file in the output directory.
+	ManifestName = "manifest.json"
+	// lockName is the advisory lock file guarding the output directory.
+	lockName = ".lock"
+
+	manifestVersion = 1
+)
+
+var now = time.Now
+
+// Manifest records metadata about every page generated in an output
+// directory.
+type Manifest struct {
+	Version int `json:"version"`
+	// Entries are keyed by the page path relative to the output directory,
+	// using forward slashes.
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// Entry describes a generated page.
+type Entry struct {
+	// Source is the absolute path of the Markdown file.
+	Source string `json:"source"`
+	Title  string `json:"title"`
+	// Theme is the theme the page was rendered with, or empty if none.
+	Theme string `json:"theme,omitempty"`
+	// Print reports whether the page was rendered for printing.
+	Print bool `json:"print,omitempty"`
+	// Hash is the hash of the generated HTML.
+	Hash string `json:"hash"`
+	// SourceHash is the hash of the Markdown the page was generated from.
+	SourceHash string    `json:"source_hash,omitempty"`
+	RenderedAt time.Time `json:"rendered_at"`
+	// MdpVersion is the version of mdp that generated the page.
+	MdpVersion string `json:"mdp_version,omitempty"`
+}
+
+// Hash returns the content hash recorded in the manifest for data.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ReadManifest reads the manifest of baseDir. A missing manifest is
+// returned as an empty one.
+func ReadManifest(baseDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, ManifestName)) //nolint:gosec // G304: manifest in the output directory
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Manifest{Version: manifestVersion, Entries: make(map[string]*Entry)}, nil
+		}
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", filepath.Join(baseDir, ManifestName), err)
+	}
+	if m.Entries == nil {
+		m.Entries = make(map[string]*Entry)
+	}
+	return &m, nil
+}
+
+// UpdateManifest applies update to the manifest of baseDir while holding
+// the output directory lock, then replaces the manifest atomically. The
+// manifest keeps its permissions.
+func UpdateManifest(baseDir string, update func(*Manifest) error) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filepath.Join(baseDir, ManifestName)); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return updateManifest(baseDir, mode, update)
+}
+
+// updateManifest is UpdateManifest writing the manifest with mode.
+func updateManifest(baseDir string, mode os.FileMode, update func(*Manifest) error) error {
+	unlock, err := lock(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		return err
+	}
+	if err := update(m); err != nil {
+		return err
+	}
+	m.Version = manifestVersion
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(baseDir, ManifestName), append(data, '\n'), mode)
+}
+
+// Key returns the manifest key of the page at path.
+func Key(baseDir, path string) (string, error) {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// lock acquires the advisory lock of baseDir and returns a function that
+// releases it.
+func lock(baseDir string) (func(), error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil { //nolint:gosec // G301: need world-readable for browser
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(baseDir, lockName), os.O_RDWR|os.O_CREATE, 0644) //nolint:gosec // G302: lock file has no content
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", baseDir, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path, syncs it
+// and renames it over path, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadManifest(t *testing.T) {
+	t.Run("missing manifest is empty", func(t *testing.T) {
+		m, err := ReadManifest(t.TempDir())
+		if err != nil {
+			t.Fatalf("ReadManifest() returned error: %v", err)
+		}
+		if m.Version != manifestVersion || len(m.Entries) != 0 {
+			t.Errorf("ReadManifest() = %+v, want empty manifest", m)
+		}
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		baseDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(baseDir, ManifestName), []byte("{"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		if _, err := ReadManifest(baseDir); err == nil || !strings.Contains(err.Error(), "invalid manifest") {
+			t.Errorf("ReadManifest() error = %v, want invalid manifest", err)
+		}
+	})
+}
+
+func TestWriteDocument_Manifest(t *testing.T) {
+	originalNow := now
+	defer func() { now = originalNow }()
+	renderedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return renderedAt }
+
+	baseDir := t.TempDir()
+	w := NewWriter(baseDir, WithVersion("v1.2.3"))
+
+	html := []byte("<h1>Guide</h1>")
+	markdown := []byte("# Guide")
+	result, err := w.WriteDocument("/docs/guide.md", html, Metadata{Title: "Guide", Theme: "default", Markdown: markdown})
+	if err != nil {
+		t.Fatalf("WriteDocument() returned error: %v", err)
+	}
+
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() returned error: %v", err)
+	}
+	key, _ := Key(baseDir, result.Path)
+	if key != "docs/guide/index.html" {
+		t.Errorf("Key() = %q, want %q", key, "docs/guide/index.html")
+	}
+	entry := m.Entries[key]
+	if entry == nil {
+		t.Fatalf("manifest has no entry for %s: %+v", key, m.Entries)
+	}
+
+	want := Entry{
+		Source:     "/docs/guide.md",
+		Title:      "Guide",
+		Theme:      "default",
+		Hash:       Hash(html),
+		SourceHash: Hash(markdown),
+		RenderedAt: renderedAt,
+		MdpVersion: "v1.2.3",
+	}
+	if *entry != want {
+		t.Errorf("entry = %+v, want %+v", *entry, want)
+	}
+}
+
+func TestUpdateManifest_Concurrent(t *testing.T) {
+	baseDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := NewWriter(baseDir)
+			if _, err := w.Write(filepath.Join("/docs", string(rune('a'+i))+".md"), []byte("x")); err != nil {
+				t.Errorf("Write() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	m, err := ReadManifest(baseDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() returned error: %v", err)
+	}
+	if len(m.Entries) != 20 {
+		t.Errorf("manifest has %d entries, want 20", len(m.Entries))
+	}
+
+	// No temporary files are left behind.
+	matches, _ := filepath.Glob(filepath.Join(baseDir, ".*.tmp"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if got := Hash([]byte("x")); !strings.HasPrefix(got, "sha256:") || got == Hash([]byte("y")) {
+		t.Errorf("Hash() = %q", got)
+	}
+}
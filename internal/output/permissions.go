@@ -0,0 +1,23 @@
+package output
+
+import "os"
+
+// Permissions selects who can read the pages in the output directory.
+type Permissions string
+
+// Permissions accepted by WithPermissions.
+const (
+	// PermissionsShared makes pages readable by everyone, so that a
+	// browser running as another user can open them.
+	PermissionsShared Permissions = "shared"
+	// PermissionsPrivate makes pages readable only by their owner.
+	PermissionsPrivate Permissions = "private"
+)
+
+// modes returns the modes of the directories and files created with p.
+func (p Permissions) modes() (dir, file os.FileMode) {
+	if p == PermissionsPrivate {
+		return 0700, 0600
+	}
+	return 0755, 0644
+}
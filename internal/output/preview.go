@@ -0,0 +1,136 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status describes whether a preview is up to date with its source.
+type Status string
+
+// Statuses of a Preview.
+const (
+	// StatusCurrent means the source has not changed since generation.
+	StatusCurrent Status = "current"
+	// StatusStale means the source has changed since generation.
+	StatusStale Status = "stale"
+	// StatusDeleted means the source no longer exists.
+	StatusDeleted Status = "deleted"
+)
+
+// Preview is a generated page in an output directory.
+type Preview struct {
+	// Path is the generated index.html file.
+	Path string
+	// Source is the Markdown file the page was generated from, or the path
+	// it would have without its extension if that file no longer exists.
+	Source string
+	// SourceExists reports whether Source was found.
+	SourceExists bool
+	// Title and Theme are empty for pages missing from the manifest.
+	Title string
+	Theme string
+	// Print reports whether the page was rendered for printing.
+	Print bool
+	// GeneratedAt is when the page was last generated.
+	GeneratedAt time.Time
+	Status      Status
+}
+
+// Previews returns the generated pages in baseDir along with the source
+// files they were generated from. The source is taken from the manifest.
+// For pages generated before the manifest existed, it is found by looking
+// for a file with the same name and any extension.
+func Previews(baseDir string) ([]Preview, error) {
+	files, err := ListFiles(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadManifest(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]Preview, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		preview := Preview{Path: file, GeneratedAt: info.ModTime()}
+		entry := manifestEntry(manifest, baseDir, file)
+		if entry != nil {
+			preview.Source = entry.Source
+			preview.Title = entry.Title
+			preview.Theme = entry.Theme
+			preview.Print = entry.Print
+			preview.GeneratedAt = entry.RenderedAt
+			_, statErr := os.Stat(entry.Source)
+			preview.SourceExists = statErr == nil
+		} else {
+			preview.Source, preview.SourceExists = findSource(baseDir, file)
+		}
+		preview.Status = status(preview, entry)
+
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
+
+// status compares the source of p with what it was generated from. The
+// source hash is compared when the manifest has one, and the modification
+// time otherwise.
+func status(p Preview, entry *Entry) Status {
+	if !p.SourceExists {
+		return StatusDeleted
+	}
+
+	if entry != nil && entry.SourceHash != "" {
+		markdown, err := os.ReadFile(p.Source)
+		if err != nil || Hash(markdown) != entry.SourceHash {
+			return StatusStale
+		}
+		return StatusCurrent
+	}
+
+	info, err := os.Stat(p.Source)
+	if err != nil || info.ModTime().After(p.GeneratedAt) {
+		return StatusStale
+	}
+	return StatusCurrent
+}
+
+func manifestEntry(m *Manifest, baseDir, path string) *Entry {
+	key, err := Key(baseDir, path)
+	if err != nil {
+		return nil
+	}
+	return m.Entries[key]
+}
+
+// findSource reverses the mirror layout for outputPath.
+func findSource(baseDir, outputPath string) (string, bool) {
+	rel, err := filepath.Rel(baseDir, filepath.Dir(outputPath))
+	if err != nil {
+		return "", false
+	}
+	base := string(filepath.Separator) + rel
+
+	entries, err := os.ReadDir(filepath.Dir(base))
+	if err != nil {
+		return base, false
+	}
+	name := filepath.Base(base)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if entryName := entry.Name(); strings.TrimSuffix(entryName, filepath.Ext(entryName)) == name {
+			return filepath.Join(filepath.Dir(base), entryName), true
+		}
+	}
+	return base, false
+}
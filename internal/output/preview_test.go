@@ -0,0 +1,194 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreviews(t *testing.T) {
+	baseDir := t.TempDir()
+	srcDir := t.TempDir()
+
+	existing := filepath.Join(srcDir, "guide.markdown")
+	if err := os.WriteFile(existing, []byte("# Guide"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	// A directory with the same name is not a source.
+	if err := os.Mkdir(filepath.Join(srcDir, "notes"), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+
+	w := NewWriter(baseDir)
+	for _, src := range []string{existing, filepath.Join(srcDir, "deleted.md"), filepath.Join(srcDir, "notes.md")} {
+		if _, err := w.Write(src, []byte("<p>x</p>")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previews, err := Previews(baseDir)
+	if err != nil {
+		t.Fatalf("Previews() returned error: %v", err)
+	}
+
+	got := make(map[string]Preview)
+	for _, p := range previews {
+		got[p.Path] = p
+	}
+
+	tests := []struct {
+		src        string
+		wantSource string
+		wantExists bool
+	}{
+		{src: existing, wantSource: existing, wantExists: true},
+		{src: filepath.Join(srcDir, "deleted.md"), wantSource: filepath.Join(srcDir, "deleted.md"), wantExists: false},
+		{src: filepath.Join(srcDir, "notes.md"), wantSource: filepath.Join(srcDir, "notes.md"), wantExists: false},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.src), func(t *testing.T) {
+			p, ok := got[w.BuildOutputPath(tt.src)]
+			if !ok {
+				t.Fatalf("Previews() missing %s", w.BuildOutputPath(tt.src))
+			}
+			if p.Source != tt.wantSource || p.SourceExists != tt.wantExists {
+				t.Errorf("Source = %q (exists %v), want %q (exists %v)", p.Source, p.SourceExists, tt.wantSource, tt.wantExists)
+			}
+			if p.GeneratedAt.IsZero() {
+				t.Error("GeneratedAt is zero")
+			}
+		})
+	}
+}
+
+func TestPreviews_WithoutManifest(t *testing.T) {
+	baseDir := t.TempDir()
+	srcDir := t.TempDir()
+
+	existing := filepath.Join(srcDir, "guide.markdown")
+	if err := os.WriteFile(existing, []byte("# Guide"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	// A directory with the same name is not a source.
+	if err := os.Mkdir(filepath.Join(srcDir, "notes"), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+
+	// Pages generated before the manifest existed.
+	w := NewWriter(baseDir)
+	for _, src := range []string{existing, filepath.Join(srcDir, "deleted.md"), filepath.Join(srcDir, "notes.md")} {
+		path := w.BuildOutputPath(src)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: test directory
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<p>x</p>"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+	}
+
+	previews, err := Previews(baseDir)
+	if err != nil {
+		t.Fatalf("Previews() returned error: %v", err)
+	}
+
+	got := make(map[string]Preview)
+	for _, p := range previews {
+		got[p.Path] = p
+	}
+
+	tests := []struct {
+		src        string
+		wantSource string
+		wantExists bool
+	}{
+		{src: existing, wantSource: existing, wantExists: true},
+		{src: filepath.Join(srcDir, "deleted.md"), wantSource: filepath.Join(srcDir, "deleted"), wantExists: false},
+		{src: filepath.Join(srcDir, "notes.md"), wantSource: filepath.Join(srcDir, "notes"), wantExists: false},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.src), func(t *testing.T) {
+			p := got[w.BuildOutputPath(tt.src)]
+			if p.Source != tt.wantSource || p.SourceExists != tt.wantExists {
+				t.Errorf("Source = %q (exists %v), want %q (exists %v)", p.Source, p.SourceExists, tt.wantSource, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestPreviews_Status(t *testing.T) {
+	baseDir := t.TempDir()
+	srcDir := t.TempDir()
+	w := NewWriter(baseDir)
+
+	write := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	current := write(t, "current.md", "# Current")
+	stale := write(t, "stale.md", "# Stale")
+	deleted := write(t, "deleted.md", "# Deleted")
+	for _, src := range []string{current, stale, deleted} {
+		markdown, err := os.ReadFile(src) //nolint:gosec // G304: test file
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.WriteDocument(src, []byte("x"), Metadata{Title: filepath.Base(src), Markdown: markdown}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, "stale.md", "# Stale, edited")
+	if err := os.Remove(deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// A page missing from the manifest falls back to modification times.
+	legacy := write(t, "legacy.md", "# Legacy")
+	legacyPage := w.BuildOutputPath(legacy)
+	if err := os.MkdirAll(filepath.Dir(legacyPage), 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacyPage, []byte("x"), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(legacyPage, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	previews, err := Previews(baseDir)
+	if err != nil {
+		t.Fatalf("Previews() returned error: %v", err)
+	}
+	got := make(map[string]Preview)
+	for _, p := range previews {
+		got[p.Path] = p
+	}
+
+	tests := []struct {
+		src       string
+		want      Status
+		wantTitle string
+	}{
+		{src: current, want: StatusCurrent, wantTitle: "current.md"},
+		{src: stale, want: StatusStale, wantTitle: "stale.md"},
+		{src: deleted, want: StatusDeleted, wantTitle: "deleted.md"},
+		{src: legacy, want: StatusStale},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.src), func(t *testing.T) {
+			p := got[w.BuildOutputPath(tt.src)]
+			if p.Status != tt.want {
+				t.Errorf("Status = %q, want %q", p.Status, tt.want)
+			}
+			if p.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", p.Title, tt.wantTitle)
+			}
+		})
+	}
+}
@@ -6,40 +6,197 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
-// Writer writes rendered HTML files to a base directory.
+// Writer writes rendered HTML files to a base directory and records them in
+// its manifest.
 type Writer struct {
-	baseDir string
+	baseDir     string
+	layout      Layout
+	permissions Permissions
+	version     string
+}
+
+// Option configures a Writer.
+type Option func(*Writer)
+
+// WithVersion sets the mdp version recorded in the manifest.
+func WithVersion(version string) Option {
+	return func(w *Writer) {
+		w.version = version
+	}
+}
+
+// WithLayout sets where pages are placed in the base directory. The
+// default is LayoutMirror.
+func WithLayout(layout Layout) Option {
+	return func(w *Writer) {
+		w.layout = layout
+	}
+}
+
+// WithPermissions sets who can read the pages written. The default is
+// PermissionsShared.
+func WithPermissions(permissions Permissions) Option {
+	return func(w *Writer) {
+		w.permissions = permissions
+	}
 }
 
 // NewWriter creates a new Writer with the specified base directory.
-func NewWriter(baseDir string) *Writer {
-	return &Writer{baseDir: baseDir}
+func NewWriter(baseDir string, opts ...Option) *Writer {
+	w := &Writer{baseDir: baseDir}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Metadata describes a rendered document for the manifest.
+type Metadata struct {
+	Title string
+	// Theme is the theme the document was rendered with, or empty if none.
+	Theme string
+	// Print reports whether the document was rendered for printing.
+	Print bool
+	// Markdown is the source the document was rendered from.
+	Markdown []byte
 }
 
 // BuildOutputPath constructs the output path for a given source file path.
+// WriteDocument uses another path if the page at this one belongs to a
+// different source, such as notes.md for notes.markdown.
 func (w *Writer) BuildOutputPath(srcPath string) string {
-	ext := filepath.Ext(srcPath)
-	pathWithoutExt := strings.TrimSuffix(srcPath, ext)
-	relativePath := strings.TrimPrefix(pathWithoutExt, "/")
-	return filepath.Join(w.baseDir, relativePath, "index.html")
+	return filepath.Join(w.baseDir, w.layout.pageDir(srcPath), "index.html")
 }
 
-func (w *Writer) Write(srcPath string, html []byte) (string, error) {
-	outputPath := w.BuildOutputPath(srcPath)
+// pagePath returns the path of the page for srcPath. The page at
+// BuildOutputPath is used unless the manifest shows that it belongs to
+// another source that still exists, in which case a hash of srcPath is
+// added to the directory name.
+func (w *Writer) pagePath(m *Manifest, srcPath string) string {
+	path := w.BuildOutputPath(srcPath)
+	entry := manifestEntry(m, w.baseDir, path)
+	if entry == nil || entry.Source == srcPath {
+		return path
+	}
+	if _, err := os.Stat(entry.Source); err != nil {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path)+"-"+pathHash(srcPath), "index.html")
+}
+
+// WriteStatus reports what WriteDocument did with a page.
+type WriteStatus int
+
+const (
+	// Updated means the page was created or its content changed.
+	Updated WriteStatus = iota
+	// Unchanged means the page already had the same content and was left
+	// as is.
+	Unchanged
+)
+
+// Result describes a page written by WriteDocument.
+type Result struct {
+	// Path is the generated index.html file.
+	Path   string
+	Status WriteStatus
+}
 
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gosec // G301: need world-readable for browser
+// Write writes html as the page for srcPath without metadata.
+func (w *Writer) Write(srcPath string, html []byte) (string, error) {
+	result, err := w.WriteDocument(srcPath, html, Metadata{})
+	if err != nil {
 		return "", err
 	}
+	return result.Path, nil
+}
 
-	if err := os.WriteFile(outputPath, html, 0644); err != nil { //nolint:gosec // G306: need world-readable for browser
-		return "", err
+// WriteDocument writes html as the page for srcPath and records it in the
+// manifest. The page is not rewritten if the manifest shows that it already
+// has the same content, so its modification time only changes with its
+// content.
+func (w *Writer) WriteDocument(srcPath string, html []byte, meta Metadata) (Result, error) {
+	result := Result{Status: Updated}
+	entry := &Entry{
+		Source:     srcPath,
+		Title:      meta.Title,
+		Theme:      meta.Theme,
+		Print:      meta.Print,
+		Hash:       Hash(html),
+		RenderedAt: now().UTC(),
+		MdpVersion: w.version,
+	}
+	if meta.Markdown != nil {
+		entry.SourceHash = Hash(meta.Markdown)
+	}
+
+	dirMode, fileMode := w.permissions.modes()
+	if err := os.MkdirAll(w.baseDir, dirMode); err != nil {
+		return Result{}, err
+	}
+	if w.permissions == PermissionsPrivate {
+		// MkdirAll leaves an existing directory as it is. Tightening the base
+		// directory closes off the page directories created while the output
+		// was shared, whose names under the mirror layout are document paths.
+		if err := os.Chmod(w.baseDir, dirMode); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// The page is written while the manifest is locked, so that concurrent
+	// writers do not interleave and its entry always describes the content
+	// on disk.
+	var writeErr error
+	err := updateManifest(w.baseDir, fileMode, func(m *Manifest) error {
+		result.Path = w.pagePath(m, srcPath)
+		key, err := Key(w.baseDir, result.Path)
+		if err != nil {
+			writeErr = err
+			return err
+		}
+		if isCurrent(result.Path, m.Entries[key], entry.Hash, len(html), fileMode) {
+			result.Status = Unchanged
+		} else if writeErr = writePage(result.Path, html, dirMode, fileMode); writeErr != nil {
+			return writeErr
+		}
+		m.Entries[key] = entry
+		return nil
+	})
+	if writeErr != nil {
+		return Result{}, writeErr
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to update manifest: %w", err)
+	}
+
+	return result, nil
+}
+
+// RemoveSource deletes the pages of srcPath in the base directory and their
+// manifest entries, and returns the pages removed. See RemoveSource.
+func (w *Writer) RemoveSource(srcPath string) ([]string, error) {
+	return RemoveSource(w.baseDir, srcPath)
+}
+
+// isCurrent reports whether the page at path, recorded in the manifest as
+// entry, already has content of the given hash and size, and the given mode.
+func isCurrent(path string, entry *Entry, hash string, size int, mode os.FileMode) bool {
+	if entry == nil || entry.Hash != hash {
+		return false
 	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode() == mode && info.Size() == int64(size)
+}
 
-	return outputPath, nil
+// writePage replaces the page at path with html atomically, so a browser
+// reloading it never sees a truncated page.
+func writePage(path string, html []byte, dirMode, fileMode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, html, fileMode)
 }
 
 // ListFiles returns a list of generated HTML files in the specified directory.
@@ -1,10 +1,15 @@
 package output
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestBuildOutputPath(t *testing.T) {
@@ -63,6 +68,296 @@ func TestWrite(t *testing.T) {
 	}
 }
 
+func TestWriteDocument_Status(t *testing.T) {
+	tmpDir := t.TempDir()
+	w := NewWriter(tmpDir)
+	srcPath := "/Users/user/docs/readme.md"
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	first, err := w.WriteDocument(srcPath, []byte("<h1>Hello</h1>"), Metadata{Markdown: []byte("# Hello")})
+	if err != nil {
+		t.Fatalf("WriteDocument() error: %v", err)
+	}
+	if first.Status != Updated {
+		t.Errorf("first WriteDocument() status = %v, want Updated", first.Status)
+	}
+	if err := os.Chtimes(first.Path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		html       string
+		prepare    func(t *testing.T)
+		wantStatus WriteStatus
+	}{
+		{
+			name:       "same content",
+			html:       "<h1>Hello</h1>",
+			wantStatus: Unchanged,
+		},
+		{
+			name:       "changed content",
+			html:       "<h1>Hello, world</h1>",
+			wantStatus: Updated,
+		},
+		{
+			name: "page removed",
+			html: "<h1>Hello, world</h1>",
+			prepare: func(t *testing.T) {
+				if err := os.Remove(first.Path); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantStatus: Updated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare(t)
+			}
+			markdown := []byte("# " + tt.name)
+			result, err := w.WriteDocument(srcPath, []byte(tt.html), Metadata{Markdown: markdown})
+			if err != nil {
+				t.Fatalf("WriteDocument() error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("WriteDocument() status = %v, want %v", result.Status, tt.wantStatus)
+			}
+
+			info, err := os.Stat(result.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rewritten := !info.ModTime().Equal(old); rewritten != (tt.wantStatus == Updated) {
+				t.Errorf("page rewritten = %v, want %v", rewritten, tt.wantStatus == Updated)
+			}
+			content, err := os.ReadFile(result.Path) //nolint:gosec // G304: path is from test
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.html {
+				t.Errorf("File content = %q, want %q", content, tt.html)
+			}
+
+			// The manifest follows the source even when the page is unchanged.
+			m, err := ReadManifest(tmpDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, _ := Key(tmpDir, result.Path)
+			if got := m.Entries[key].SourceHash; got != Hash(markdown) {
+				t.Errorf("manifest source_hash = %q, want %q", got, Hash(markdown))
+			}
+
+			if err := os.Chtimes(result.Path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestWriteDocument_Collision(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := t.TempDir()
+	w := NewWriter(tmpDir)
+
+	sources := make(map[string]string)
+	for _, name := range []string{"notes.md", "notes.markdown", "notes.txt"} {
+		sources[name] = filepath.Join(srcDir, name)
+		if err := os.WriteFile(sources[name], []byte(name), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+	}
+
+	write := func(t *testing.T, name string) string {
+		t.Helper()
+		result, err := w.WriteDocument(sources[name], []byte(name), Metadata{})
+		if err != nil {
+			t.Fatalf("WriteDocument() error: %v", err)
+		}
+		return result.Path
+	}
+
+	md := write(t, "notes.md")
+	markdown := write(t, "notes.markdown")
+	txt := write(t, "notes.txt")
+
+	if md != w.BuildOutputPath(sources["notes.md"]) {
+		t.Errorf("first source path = %q, want %q", md, w.BuildOutputPath(sources["notes.md"]))
+	}
+	if md == markdown || md == txt || markdown == txt {
+		t.Fatalf("pages collide: %q, %q, %q", md, markdown, txt)
+	}
+	for name, path := range map[string]string{"notes.md": md, "notes.markdown": markdown, "notes.txt": txt} {
+		content, err := os.ReadFile(path) //nolint:gosec // G304: path is from test
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != name {
+			t.Errorf("page %s = %q, want %q", path, content, name)
+		}
+	}
+
+	if again := write(t, "notes.markdown"); again != markdown {
+		t.Errorf("rewritten page path = %q, want %q", again, markdown)
+	}
+
+	// Once the owner of the page is gone, another source takes it over.
+	if err := os.Remove(sources["notes.md"]); err != nil {
+		t.Fatal(err)
+	}
+	if got := write(t, "notes.txt"); got != md {
+		t.Errorf("page path after owner removed = %q, want %q", got, md)
+	}
+}
+
+func TestWriteDocument_Permissions(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "output")
+	srcPath := "/Users/user/docs/readme.md"
+	html := []byte("<h1>Hello</h1>")
+
+	tests := []struct {
+		name        string
+		permissions Permissions
+		wantBase    os.FileMode
+		wantFile    os.FileMode
+		wantStatus  WriteStatus
+	}{
+		{name: "shared", permissions: PermissionsShared, wantBase: 0755, wantFile: 0644, wantStatus: Updated},
+		{name: "private rewrites unchanged page", permissions: PermissionsPrivate, wantBase: 0700, wantFile: 0600, wantStatus: Updated},
+		{name: "private again", permissions: PermissionsPrivate, wantBase: 0700, wantFile: 0600, wantStatus: Unchanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(baseDir, WithPermissions(tt.permissions))
+			result, err := w.WriteDocument(srcPath, html, Metadata{})
+			if err != nil {
+				t.Fatalf("WriteDocument() error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("WriteDocument() status = %v, want %v", result.Status, tt.wantStatus)
+			}
+
+			// Page directories keep the mode they were created with; a private
+			// base directory closes them off.
+			for path, want := range map[string]os.FileMode{
+				baseDir:                              tt.wantBase,
+				filepath.Dir(result.Path):            0755,
+				result.Path:                          tt.wantFile,
+				filepath.Join(baseDir, ManifestName): tt.wantFile,
+			} {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := info.Mode().Perm(); got != want {
+					t.Errorf("mode of %s = %o, want %o", path, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("private directories", func(t *testing.T) {
+		privateDir := filepath.Join(t.TempDir(), "private")
+		result, err := NewWriter(privateDir, WithPermissions(PermissionsPrivate)).WriteDocument(srcPath, html, Metadata{})
+		if err != nil {
+			t.Fatalf("WriteDocument() error: %v", err)
+		}
+		for dir := filepath.Dir(result.Path); dir != filepath.Dir(privateDir); dir = filepath.Dir(dir) {
+			info, err := os.Stat(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != 0700 {
+				t.Errorf("mode of %s = %o, want 700", dir, got)
+			}
+		}
+	})
+}
+
+func TestWriteDocument_Concurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcPath := "/Users/user/docs/readme.md"
+	outputPath := NewWriter(tmpDir).BuildOutputPath(srcPath)
+
+	versions := make([][]byte, 8)
+	for i := range versions {
+		versions[i] = bytes.Repeat([]byte(fmt.Sprintf("<p>version %d</p>\n", i)), 10000)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	partial := make(chan []byte, 1)
+	go func() {
+		// Every read of the page must see one complete version.
+		for {
+			select {
+			case <-done:
+				close(partial)
+				return
+			default:
+			}
+			content, err := os.ReadFile(outputPath) //nolint:gosec // G304: path is from test
+			if err != nil {
+				continue
+			}
+			complete := false
+			for _, version := range versions {
+				complete = complete || bytes.Equal(content, version)
+			}
+			if !complete {
+				partial <- content
+				close(partial)
+				return
+			}
+		}
+	}()
+
+	for _, version := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A Writer per goroutine, as with separate mdp processes.
+			if _, err := NewWriter(tmpDir).WriteDocument(srcPath, version, Metadata{}); err != nil {
+				t.Errorf("WriteDocument() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+
+	if content, ok := <-partial; ok {
+		t.Fatalf("read a partial page of %d bytes", len(content))
+	}
+
+	content, err := os.ReadFile(outputPath) //nolint:gosec // G304: path is from test
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := Key(tmpDir, outputPath)
+	if m.Entries[key].Hash != Hash(content) {
+		t.Errorf("manifest hash %s does not match the page", m.Entries[key].Hash)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(outputPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
 func TestListFiles_DirectoryNotExist(t *testing.T) {
 	_, err := ListFiles("/non/existent/directory")
 	if err == nil {
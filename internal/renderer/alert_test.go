@@ -0,0 +1,112 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_Alerts(t *testing.T) {
+	r, err := NewRenderer("", "")
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		markdown  string
+		want      []string
+		forbidden []string
+	}{
+		{
+			name:     "note",
+			markdown: "> [!NOTE]\n> Useful **information**.",
+			want: []string{
+				`<div class="markdown-alert markdown-alert-note">`,
+				`<p class="markdown-alert-title"><svg class="octicon octicon-info mr-2"`,
+				"</svg>Note</p>",
+				"<p>Useful <strong>information</strong>.</p>\n</div>",
+			},
+			forbidden: []string{"[!NOTE]", "<blockquote>"},
+		},
+		{
+			name:     "every type, case-insensitive",
+			markdown: "> [!tip]\n> a\n\n> [!Important]\n> b\n\n> [!WARNING]\n> c\n\n> [!CAUTION]\n> d",
+			want: []string{
+				"markdown-alert-tip", "octicon-light-bulb", "</svg>Tip</p>",
+				"markdown-alert-important", "octicon-report", "</svg>Important</p>",
+				"markdown-alert-warning", "octicon-alert", "</svg>Warning</p>",
+				"markdown-alert-caution", "octicon-stop", "</svg>Caution</p>",
+			},
+			forbidden: []string{"<blockquote>"},
+		},
+		{
+			name:      "several paragraphs",
+			markdown:  "> [!NOTE]\n>\n> One.\n>\n> Two.",
+			want:      []string{"</svg>Note</p>\n<p>One.</p>\n<p>Two.</p>\n</div>"},
+			forbidden: []string{"<p></p>"},
+		},
+		{
+			name:      "nested in a list",
+			markdown:  "- > [!NOTE]\n  > nested",
+			want:      []string{"<li>\n<blockquote>", "[!NOTE]"},
+			forbidden: []string{"markdown-alert"},
+		},
+		{
+			name:      "nested in a blockquote",
+			markdown:  "> > [!NOTE]\n> > nested",
+			want:      []string{"<blockquote>\n<blockquote>", "[!NOTE]"},
+			forbidden: []string{"markdown-alert"},
+		},
+		{
+			name:      "marker not alone on its line",
+			markdown:  "> [!NOTE] inline\n> text",
+			want:      []string{"<blockquote>", "[!NOTE] inline"},
+			forbidden: []string{"markdown-alert"},
+		},
+		{
+			name:      "unknown type",
+			markdown:  "> [!DANGER]\n> text",
+			want:      []string{"<blockquote>"},
+			forbidden: []string{"markdown-alert"},
+		},
+		{
+			name:      "plain blockquote",
+			markdown:  "> quote",
+			want:      []string{"<blockquote>\n<p>quote</p>\n</blockquote>"},
+			forbidden: []string{"markdown-alert"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Render() output does not contain %q\n%s", want, html)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(html), forbidden) {
+					t.Errorf("Render() output contains %q\n%s", forbidden, html)
+				}
+			}
+		})
+	}
+
+	t.Run("sanitize keeps alerts", func(t *testing.T) {
+		r, err := NewRenderer("", "", WithRawHTML("sanitize"))
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+		html, err := r.Render([]byte("> [!NOTE]\n> text"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), "<svg class=\"octicon octicon-info mr-2\"") {
+			t.Errorf("Render() output lost the alert icon\n%s", html)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package renderer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji"
+	east "github.com/yuin/goldmark-emoji/ast"
+	"github.com/yuin/goldmark-emoji/definition"
+	"github.com/yuin/goldmark/util"
+)
+
+// Emoji outputs accepted by WithEmoji.
+const (
+	EmojiUnicode = "unicode"
+	EmojiImage   = "image"
+)
+
+// githubEmojiURL is where GitHub serves the image of an emoji, named by its
+// code points.
+const githubEmojiURL = "https://github.githubassets.com/images/icons/emoji/unicode/%s.png"
+
+// WithEmoji sets how emoji shortcodes such as :tada: are rendered when the
+// emoji feature is on. output is "unicode", the default, for the emoji
+// character, or "image" for GitHub's image of it. aliases adds shortcodes
+// or replaces GitHub's: each value is the text to render, the URL of an
+// image, or another shortcode such as ":+1:".
+func WithEmoji(output string, aliases map[string]string) Option {
+	return func(r *Renderer) {
+		r.emojiOutput = output
+		r.emojiAliases = aliases
+	}
+}
+
+// emojiTable is the set of shortcodes a renderer knows.
+type emojiTable struct {
+	emojis definition.Emojis
+	// images maps the shortcodes of image aliases to their URLs.
+	images map[string]string
+}
+
+// newEmojiTable returns GitHub's shortcodes with aliases added on top.
+func newEmojiTable(aliases map[string]string) (*emojiTable, error) {
+	github := definition.Github()
+	if len(aliases) == 0 {
+		return &emojiTable{emojis: github}, nil
+	}
+
+	table := &emojiTable{images: make(map[string]string)}
+	var custom []definition.Emoji
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		value := aliases[name]
+		switch {
+		case strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://"):
+			// Image aliases have no Unicode; the renderer looks them up by name.
+			custom = append(custom, definition.NewEmoji(name, nil, name))
+			table.images[name] = value
+		case len(value) > 2 && strings.HasPrefix(value, ":") && strings.HasSuffix(value, ":"):
+			target, ok := github.Get(value[1 : len(value)-1])
+			if !ok {
+				return nil, fmt.Errorf("emoji alias %q: unknown shortcode %s", name, value)
+			}
+			custom = append(custom, definition.NewEmoji(target.Name, target.Unicode, name))
+		default:
+			custom = append(custom, definition.NewEmoji(name, []rune(value), name))
+		}
+	}
+
+	// Aliases are looked up first, so they can replace GitHub's shortcodes.
+	table.emojis = definition.NewEmojis(custom...)
+	table.emojis.Add(github)
+	return table, nil
+}
+
+// extension returns the goldmark extension that renders the table's
+// shortcodes as output.
+func (t *emojiTable) extension(output string) goldmark.Extender {
+	render := func(w util.BufWriter, _ []byte, n *east.Emoji, config *emoji.RendererConfig) {
+		if src, ok := t.images[n.Value.Name]; ok && !n.Value.IsUnicode() {
+			writeEmojiImage(w, n.ShortName, src, config.XHTML)
+			return
+		}
+		if output == EmojiImage {
+			writeEmojiImage(w, n.ShortName, fmt.Sprintf(githubEmojiURL, codePoints(n.Value.Unicode)), config.XHTML)
+			return
+		}
+		// Text aliases come from the config and may contain markup, which
+		// must not bypass the raw_html setting.
+		_, _ = w.Write(util.EscapeHTML([]byte(string(n.Value.Unicode))))
+	}
+	return emoji.New(
+		emoji.WithEmojis(t.emojis),
+		emoji.WithRenderingMethod(emoji.Func),
+		emoji.WithRendererFunc(render),
+	)
+}
+
+// writeEmojiImage writes an emoji image the way GitHub does.
+func writeEmojiImage(w util.BufWriter, shortName []byte, src string, xhtml bool) {
+	code := util.EscapeHTML([]byte(":" + string(shortName) + ":"))
+	_, _ = fmt.Fprintf(w, `<img class="emoji" title="%s" alt="%s" src="%s" height="20" width="20" align="absmiddle"`,
+		code, code, util.EscapeHTML([]byte(src)))
+	if xhtml {
+		_, _ = w.WriteString(" />")
+	} else {
+		_, _ = w.WriteString(">")
+	}
+}
+
+// codePoints returns the file name GitHub uses for the image of an emoji:
+// its code points in hex without variation selectors, such as "1f44d" or
+// "1f1ef-1f1f5".
+func codePoints(runes []rune) string {
+	var points []string
+	for _, r := range runes {
+		if r == 0xFE0F {
+			continue
+		}
+		points = append(points, fmt.Sprintf("%x", r))
+	}
+	return strings.Join(points, "-")
+}
@@ -0,0 +1,114 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_Emoji(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		markdown  string
+		want      []string
+		forbidden []string
+	}{
+		{
+			name:     "unicode",
+			markdown: ":tada: :warning: :+1: :thumbsup:",
+			want:     []string{"<p>🎉 ⚠️ 👍 👍</p>"},
+		},
+		{
+			name:     "unknown shortcodes and times are left alone",
+			markdown: ":not_an_emoji: at 10:30:00",
+			want:     []string{"<p>:not_an_emoji: at 10:30:00</p>"},
+		},
+		{
+			name:      "code",
+			markdown:  "`:tada:`",
+			want:      []string{"<code>:tada:</code>"},
+			forbidden: []string{"🎉"},
+		},
+		{
+			name:     "image",
+			opts:     []Option{WithEmoji(EmojiImage, nil)},
+			markdown: ":+1: :heart: :jp:",
+			want: []string{
+				`<img class="emoji" title=":+1:" alt=":+1:" src="https://github.githubassets.com/images/icons/emoji/unicode/1f44d.png" height="20" width="20" align="absmiddle">`,
+				`src="https://github.githubassets.com/images/icons/emoji/unicode/2764.png"`,
+				`src="https://github.githubassets.com/images/icons/emoji/unicode/1f1ef-1f1f5.png"`,
+			},
+		},
+		{
+			name:     "image in XHTML",
+			opts:     []Option{WithEmoji(EmojiImage, nil), WithMarkdown(Features{Emoji: true, XHTML: true})},
+			markdown: ":tada:",
+			want:     []string{`align="absmiddle" />`},
+		},
+		{
+			name: "aliases",
+			opts: []Option{WithEmoji(EmojiUnicode, map[string]string{
+				"lgtm":   ":+1:",
+				"shipit": "https://example.com/shipit.png",
+				"tada":   "🥳",
+				"mdp":    "M↓",
+			})},
+			markdown: ":lgtm: :shipit: :tada: :mdp: :warning:",
+			want: []string{
+				"<p>👍 ",
+				`<img class="emoji" title=":shipit:" alt=":shipit:" src="https://example.com/shipit.png" height="20" width="20" align="absmiddle">`,
+				" 🥳 M↓ ⚠️</p>",
+			},
+		},
+		{
+			name:      "text aliases are escaped",
+			opts:      []Option{WithEmoji(EmojiUnicode, map[string]string{"xss": `<img src=x onerror="alert(1)">`, "amp": "R&D"})},
+			markdown:  ":xss: :amp:",
+			want:      []string{`<p>&lt;img src=x onerror=&quot;alert(1)&quot;&gt; R&amp;D</p>`},
+			forbidden: []string{"<img"},
+		},
+		{
+			name:     "alias to a shortcode rendered as an image",
+			opts:     []Option{WithEmoji(EmojiImage, map[string]string{"lgtm": ":+1:"})},
+			markdown: ":lgtm:",
+			want:     []string{`title=":lgtm:" alt=":lgtm:" src="https://github.githubassets.com/images/icons/emoji/unicode/1f44d.png"`},
+		},
+		{
+			name:     "turned off",
+			opts:     []Option{WithMarkdown(Features{})},
+			markdown: ":tada:",
+			want:     []string{"<p>:tada:</p>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer("", "", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Render() = %q, want it to contain %q", html, want)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(html), forbidden) {
+					t.Errorf("Render() = %q, want it not to contain %q", html, forbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRenderer_EmojiAliasToUnknownShortcode(t *testing.T) {
+	_, err := NewRenderer("", "", WithEmoji(EmojiUnicode, map[string]string{"lgtm": ":no_such_emoji:"}))
+	if err == nil || !strings.Contains(err.Error(), `emoji alias "lgtm": unknown shortcode :no_such_emoji:`) {
+		t.Errorf("NewRenderer() error = %v, want unknown shortcode", err)
+	}
+}
@@ -0,0 +1,186 @@
+package renderer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmrenderer "github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Markdown presets accepted by Preset.
+const (
+	PresetCommonMark    = "commonmark"
+	PresetGFM           = "gfm"
+	PresetGitHubComment = "github-comment"
+)
+
+// Features are the Markdown syntax extensions a renderer supports on top of
+// CommonMark.
+type Features struct {
+	Tables        bool
+	Strikethrough bool
+	TaskLists     bool
+	Linkify       bool
+	Alerts        bool
+	// Emoji renders shortcodes such as :tada: as emoji.
+	Emoji           bool
+	Footnotes       bool
+	DefinitionLists bool
+	Typographer     bool
+	// HardWraps renders every newline in a paragraph as a line break, as
+	// GitHub does in comments.
+	HardWraps bool
+	// XHTML renders void elements such as <br /> in XHTML style.
+	XHTML bool
+}
+
+// Preset returns the features of the named preset: "commonmark" has none,
+// "gfm" has the GitHub Flavored Markdown extensions, alerts and emoji, and
+// "github-comment" also has hard wraps.
+func Preset(name string) (Features, error) {
+	switch name {
+	case PresetCommonMark:
+		return Features{}, nil
+	case PresetGFM:
+		return Features{Tables: true, Strikethrough: true, TaskLists: true, Linkify: true, Alerts: true, Emoji: true}, nil
+	case PresetGitHubComment:
+		f, _ := Preset(PresetGFM)
+		f.HardWraps = true
+		return f, nil
+	}
+	return Features{}, fmt.Errorf("unknown markdown preset %q: must be one of commonmark, gfm, github-comment", name)
+}
+
+// featureNames are the features that can be turned on or off by name, as in
+// the front-matter "markdown" key.
+var featureNames = []string{"footnotes", "definition_lists", "typographer", "hard_wraps", "xhtml", "linkify", "emoji"}
+
+// Set turns the named feature on or off. The names are footnotes,
+// definition_lists, typographer, hard_wraps, xhtml, linkify and emoji.
+func (f *Features) Set(name string, enabled bool) error {
+	switch name {
+	case "footnotes":
+		f.Footnotes = enabled
+	case "definition_lists":
+		f.DefinitionLists = enabled
+	case "typographer":
+		f.Typographer = enabled
+	case "hard_wraps":
+		f.HardWraps = enabled
+	case "xhtml":
+		f.XHTML = enabled
+	case "linkify":
+		f.Linkify = enabled
+	case "emoji":
+		f.Emoji = enabled
+	default:
+		return fmt.Errorf("unknown markdown feature %q: must be one of %s", name, strings.Join(featureNames, ", "))
+	}
+	return nil
+}
+
+// WithMarkdown sets the Markdown features documents are rendered with. The
+// default is the "gfm" preset. A document can change them with the
+// front-matter "markdown" key.
+func WithMarkdown(features Features) Option {
+	return func(r *Renderer) {
+		r.features = features
+	}
+}
+
+// frontMatterFeatures returns f as changed by the front-matter "markdown"
+// value: either a preset name, or a mapping with an optional "preset" and
+// features to turn on or off.
+func frontMatterFeatures(f Features, value any) (Features, error) {
+	if name, ok := value.(string); ok {
+		return Preset(name)
+	}
+
+	settings, ok := stringMap(value)
+	if !ok {
+		return Features{}, fmt.Errorf("invalid front-matter markdown: must be a preset name or a mapping")
+	}
+	if preset, ok := settings["preset"]; ok {
+		name, ok := preset.(string)
+		if !ok {
+			return Features{}, fmt.Errorf("invalid front-matter markdown.preset: must be a string")
+		}
+		var err error
+		if f, err = Preset(name); err != nil {
+			return Features{}, err
+		}
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if name != "preset" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		enabled, ok := settings[name].(bool)
+		if !ok {
+			return Features{}, fmt.Errorf("invalid front-matter markdown.%s: must be true or false", name)
+		}
+		if err := f.Set(name, enabled); err != nil {
+			return Features{}, err
+		}
+	}
+	return f, nil
+}
+
+// stringMap converts a decoded front-matter mapping to a map with string
+// keys.
+func stringMap(value any) (map[string]any, bool) {
+	switch m := value.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		converted := make(map[string]any, len(m))
+		for k, v := range m {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			converted[key] = v
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// extensions returns the goldmark extensions for f.
+func (f Features) extensions() []goldmark.Extender {
+	var exts []goldmark.Extender
+	add := func(enabled bool, ext goldmark.Extender) {
+		if enabled {
+			exts = append(exts, ext)
+		}
+	}
+	add(f.Tables, extension.Table)
+	add(f.Strikethrough, extension.Strikethrough)
+	add(f.TaskLists, extension.TaskList)
+	add(f.Linkify, extension.Linkify)
+	add(f.Alerts, alerts{})
+	add(f.Footnotes, extension.Footnote)
+	add(f.DefinitionLists, extension.DefinitionList)
+	add(f.Typographer, extension.Typographer)
+	return exts
+}
+
+// rendererOptions returns the goldmark HTML renderer options for f.
+func (f Features) rendererOptions() []gmrenderer.Option {
+	var opts []gmrenderer.Option
+	if f.HardWraps {
+		opts = append(opts, html.WithHardWraps())
+	}
+	if f.XHTML {
+		opts = append(opts, html.WithXHTML())
+	}
+	return opts
+}
@@ -0,0 +1,206 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Features
+		wantErr bool
+	}{
+		{name: PresetCommonMark, want: Features{}},
+		{name: PresetGFM, want: Features{Tables: true, Strikethrough: true, TaskLists: true, Linkify: true, Alerts: true, Emoji: true}},
+		{name: PresetGitHubComment, want: Features{Tables: true, Strikethrough: true, TaskLists: true, Linkify: true, Alerts: true, Emoji: true, HardWraps: true}},
+		{name: "markdown-it", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Preset(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Preset() returned nil error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Preset() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Preset() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Features(t *testing.T) {
+	gfm, _ := Preset(PresetGFM)
+	commonMark, _ := Preset(PresetCommonMark)
+
+	tests := []struct {
+		name      string
+		features  Features
+		markdown  string
+		want      []string
+		forbidden []string
+	}{
+		{
+			name:     "gfm",
+			features: gfm,
+			markdown: "| a |\n|---|\n| b |\n\n~~old~~ https://example.com\n\n> [!NOTE]\n> n",
+			want:     []string{"<table>", "<del>old</del>", `<a href="https://example.com">`, "markdown-alert-note"},
+		},
+		{
+			name:      "commonmark",
+			features:  commonMark,
+			markdown:  "| a |\n|---|\n| b |\n\n~~old~~ https://example.com\n\n> [!NOTE]\n> n",
+			want:      []string{"~~old~~ https://example.com", "<blockquote>"},
+			forbidden: []string{"<table>", "<del>", "<a ", "markdown-alert"},
+		},
+		{
+			name:     "footnotes",
+			features: Features{Footnotes: true},
+			markdown: "Text[^1].\n\n[^1]: Note.",
+			want:     []string{`<sup id="fnref:1">`, `<div class="footnotes" role="doc-endnotes">`},
+		},
+		{
+			name:     "definition lists",
+			features: Features{DefinitionLists: true},
+			markdown: "Term\n: Definition",
+			want:     []string{"<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>"},
+		},
+		{
+			name:     "typographer",
+			features: Features{Typographer: true},
+			markdown: `"Quoted" -- text...`,
+			want:     []string{"&ldquo;Quoted&rdquo; &ndash; text&hellip;"},
+		},
+		{
+			name:      "hard wraps",
+			features:  Features{HardWraps: true},
+			markdown:  "one\ntwo",
+			want:      []string{"one<br>\ntwo"},
+			forbidden: []string{"one\ntwo"},
+		},
+		{
+			name:     "xhtml",
+			features: Features{HardWraps: true, XHTML: true},
+			markdown: "one\ntwo\n\n---",
+			want:     []string{"one<br />\ntwo", "<hr />"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer("", "", WithMarkdown(tt.features))
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Render() = %q, want it to contain %q", html, want)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(html), forbidden) {
+					t.Errorf("Render() = %q, want it not to contain %q", html, forbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDocument_FrontMatterMarkdown(t *testing.T) {
+	r, err := NewRenderer("", "")
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		markdown  string
+		want      []string
+		forbidden []string
+		wantErr   string
+	}{
+		{
+			name:     "preset name",
+			markdown: "---\nmarkdown: github-comment\n---\none\ntwo",
+			want:     []string{"one<br>\ntwo"},
+		},
+		{
+			name:      "preset in a mapping",
+			markdown:  "---\nmarkdown:\n  preset: commonmark\n---\n~~old~~",
+			want:      []string{"~~old~~"},
+			forbidden: []string{"<del>"},
+		},
+		{
+			name:     "features on top of the configured preset",
+			markdown: "---\nmarkdown:\n  footnotes: true\n---\n~~old~~[^1]\n\n[^1]: Note.",
+			want:     []string{"<del>old</del>", `<sup id="fnref:1">`},
+		},
+		{
+			name:     "feature turned off",
+			markdown: "---\nmarkdown:\n  linkify: false\n---\nhttps://example.com",
+			want:     []string{"<p>https://example.com</p>"},
+		},
+		{
+			name:     "title from the reparsed document",
+			markdown: "---\nmarkdown:\n  typographer: true\n---\n# \"Notes\"",
+			want:     []string{"<h1>&ldquo;Notes&rdquo;</h1>"},
+		},
+		{
+			name:     "unknown preset",
+			markdown: "---\nmarkdown: markdown-it\n---\nText",
+			wantErr:  "unknown markdown preset",
+		},
+		{
+			name:     "unknown feature",
+			markdown: "---\nmarkdown:\n  mermaid: true\n---\nText",
+			wantErr:  "unknown markdown feature",
+		},
+		{
+			name:     "feature not a bool",
+			markdown: "---\nmarkdown:\n  footnotes: yes please\n---\nText",
+			wantErr:  "must be true or false",
+		},
+		{
+			name:     "not a preset or mapping",
+			markdown: "---\nmarkdown: [gfm]\n---\nText",
+			wantErr:  "must be a preset name or a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := r.RenderDocument([]byte(tt.markdown))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RenderDocument() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderDocument() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(doc.HTML), want) {
+					t.Errorf("HTML = %q, want it to contain %q", doc.HTML, want)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(doc.HTML), forbidden) {
+					t.Errorf("HTML = %q, want it not to contain %q", doc.HTML, forbidden)
+				}
+			}
+		})
+	}
+}
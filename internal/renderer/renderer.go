@@ -3,36 +3,169 @@ package renderer
 
 import (
 	"bytes"
+	_ "embed"
+	"fmt"
 	"html/template"
 	"os"
-	"path/filepath"
+	"sync"
 
 	"github.com/yuin/goldmark"
 	meta "github.com/yuin/goldmark-meta"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	gmrenderer "github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
+//go:embed print.css
+var defaultPrintCSS string
+
 // Renderer converts Markdown to HTML using an optional theme template.
+//
+// The theme is resolved per document with the following precedence:
+// the override given by WithThemeOverride, the "theme" front-matter key,
+// and finally the theme passed to NewRenderer.
 type Renderer struct {
-	tmpl *template.Template
+	configDir         string
+	defaultTheme      string
+	themeOverride     string
+	colorScheme       string
+	colorSchemeToggle bool
+	print             bool
+	printCSSPath      string
+	printCSS          string
+	rawHTML           string
+	features          Features
+	emojiOutput       string
+	emojiAliases      map[string]string
+	emoji             goldmark.Extender
+
+	mu        sync.Mutex
+	templates map[string]*template.Template
+}
+
+// Option configures optional Renderer behavior.
+type Option func(*Renderer)
+
+// WithThemeOverride makes the renderer use the named theme for every
+// document, ignoring the "theme" front-matter key.
+func WithThemeOverride(themeName string) Option {
+	return func(r *Renderer) {
+		r.themeOverride = themeName
+	}
 }
 
 type templateData struct {
 	Title   string
 	Content template.HTML
+	// ColorScheme is "auto", "light" or "dark".
+	ColorScheme string
+	// ColorSchemeToggle reports whether the page should offer a color scheme switch.
+	ColorSchemeToggle bool
+	// Print reports whether the document is rendered for printing.
+	Print bool
+	// PrintCSS is the paged-media stylesheet; it is empty unless Print is set.
+	PrintCSS template.CSS
 }
 
-// NewRenderer creates a new Renderer with the specified theme.
-func NewRenderer(configDir string, themeName string) (*Renderer, error) {
-	if themeName == "" {
-		return &Renderer{tmpl: nil}, nil
+// WithColorScheme sets the color scheme passed to theme templates.
+// It is one of "auto", "light" or "dark"; the default is "auto".
+func WithColorScheme(scheme string) Option {
+	return func(r *Renderer) {
+		r.colorScheme = scheme
+	}
+}
+
+// WithColorSchemeToggle asks theme templates to include an in-page color
+// scheme switch.
+func WithColorSchemeToggle(enabled bool) Option {
+	return func(r *Renderer) {
+		r.colorSchemeToggle = enabled
+	}
+}
+
+// WithPrint renders every document in print mode, as if its front-matter
+// contained "media: print".
+func WithPrint(enabled bool) Option {
+	return func(r *Renderer) {
+		r.print = enabled
+	}
+}
+
+// WithPrintCSS replaces the bundled paged-media stylesheet used in print
+// mode with the stylesheet at path.
+func WithPrintCSS(path string) Option {
+	return func(r *Renderer) {
+		r.printCSSPath = path
+	}
+}
+
+// WithRawHTML sets how raw HTML in Markdown is rendered: "omit" replaces
+// it with a comment, "sanitize" keeps what a GitHub-like allowlist permits
+// and "allow" keeps it verbatim. The default is "omit".
+func WithRawHTML(policy string) Option {
+	return func(r *Renderer) {
+		r.rawHTML = policy
+	}
+}
+
+// NewRenderer creates a new Renderer with the specified default theme.
+// The default theme and the override theme, if any, are loaded eagerly so
+// that a misconfigured theme is reported before any document is rendered.
+func NewRenderer(configDir string, themeName string, opts ...Option) (*Renderer, error) {
+	features, _ := Preset(PresetGFM)
+	r := &Renderer{
+		configDir:    configDir,
+		defaultTheme: themeName,
+		colorScheme:  "auto",
+		printCSS:     defaultPrintCSS,
+		features:     features,
+		emojiOutput:  EmojiUnicode,
+		templates:    make(map[string]*template.Template),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
 
-	themePath := filepath.Join(configDir, "themes", themeName+".html")
-	content, err := os.ReadFile(themePath) //nolint:gosec // G304: theme path is from trusted config
+	emojis, err := newEmojiTable(r.emojiAliases)
+	if err != nil {
+		return nil, err
+	}
+	r.emoji = emojis.extension(r.emojiOutput)
+
+	if r.printCSSPath != "" {
+		css, err := os.ReadFile(r.printCSSPath) //nolint:gosec // G304: path is from trusted config
+		if err != nil {
+			return nil, fmt.Errorf("failed to read print_css: %w", err)
+		}
+		r.printCSS = string(css)
+	}
+
+	for _, name := range []string{r.themeOverride, r.defaultTheme} {
+		if name == "" {
+			continue
+		}
+		if _, err := r.template(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// template returns the parsed template for the named theme, loading and
+// caching it on first use.
+func (r *Renderer) template(themeName string) (*template.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tmpl, ok := r.templates[themeName]; ok {
+		return tmpl, nil
+	}
+
+	content, _, err := LoadTheme(r.configDir, themeName)
 	if err != nil {
 		return nil, err
 	}
@@ -42,23 +175,132 @@ func NewRenderer(configDir string, themeName string) (*Renderer, error) {
 		return nil, err
 	}
 
-	return &Renderer{tmpl: tmpl}, nil
+	r.templates[themeName] = tmpl
+	return tmpl, nil
 }
 
-// Render converts Markdown to HTML, applying the theme template if configured.
-func (r *Renderer) Render(markdown []byte) ([]byte, error) {
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			meta.Meta,
-		),
-	)
+// resolveTheme returns the theme name to use for a document.
+// Documents rendered for printing always get a theme, falling back to the
+// built-in default theme, because a bare HTML fragment cannot carry the
+// paged-media stylesheet.
+func (r *Renderer) resolveTheme(metaData map[string]any, printMode bool) string {
+	if r.themeOverride != "" {
+		return r.themeOverride
+	}
+	if theme, ok := metaData["theme"].(string); ok && theme != "" {
+		return theme
+	}
+	if r.defaultTheme == "" && printMode {
+		return DefaultThemeName
+	}
+	return r.defaultTheme
+}
+
+// isPrint reports whether a document is rendered for printing.
+func (r *Renderer) isPrint(metaData map[string]any) bool {
+	if r.print {
+		return true
+	}
+	media, _ := metaData["media"].(string)
+	return media == "print"
+}
 
+// Document is a rendered Markdown document.
+type Document struct {
+	// HTML is the rendered page, or a bare fragment if no theme applies.
+	HTML []byte
+	// Title is the document title used for the page.
+	Title string
+	// Theme is the theme the page was rendered with, or empty if none.
+	Theme string
+	// Print reports whether the document was rendered for printing.
+	Print bool
+	// Private reports whether the front-matter marks the document as
+	// private with "private: true".
+	Private bool
+}
+
+// IsPrivate reports whether the front-matter of markdown marks the
+// document as private, like Document.Private, without rendering it.
+func IsPrivate(markdown []byte) bool {
 	context := parser.NewContext()
+	goldmark.New(goldmark.WithExtensions(meta.Meta)).Parser().Parse(text.NewReader(markdown), parser.WithContext(context))
+	private, _ := meta.Get(context)["private"].(bool)
+	return private
+}
 
-	// パースは1回だけ
+// markdown returns the Markdown converter for features and the renderer's
+// options. Documents printed get heading IDs, so that links in a table of
+// contents can point at their page.
+func (r *Renderer) markdown(features Features, printMode bool) goldmark.Markdown {
+	opts := []goldmark.Option{
+		goldmark.WithExtensions(meta.Meta, toc{}),
+		goldmark.WithExtensions(features.extensions()...),
+		goldmark.WithRendererOptions(features.rendererOptions()...),
+	}
+	if printMode {
+		opts = append(opts, goldmark.WithParserOptions(parser.WithAutoHeadingID()))
+	}
+	if features.Emoji {
+		opts = append(opts, goldmark.WithExtensions(r.emoji))
+	}
+	switch r.rawHTML {
+	case "sanitize":
+		// Unsafe rendering is not turned on: the sanitizing renderer writes raw
+		// HTML itself, and links and images written in Markdown keep
+		// goldmark's filter for dangerous URLs.
+		opts = append(opts, goldmark.WithRendererOptions(
+			gmrenderer.WithNodeRenderers(util.Prioritized(newSanitizingRenderer(), 100)),
+		))
+	case "allow":
+		opts = append(opts, goldmark.WithRendererOptions(html.WithUnsafe()))
+	}
+	return goldmark.New(opts...)
+}
+
+// parse parses markdown with the renderer's features, and again if the
+// front-matter changes them with the "markdown" key or asks for print mode.
+func (r *Renderer) parse(markdown []byte) (goldmark.Markdown, ast.Node, parser.Context, error) {
+	md := r.markdown(r.features, r.print)
+	context := parser.NewContext()
 	doc := md.Parser().Parse(text.NewReader(markdown), parser.WithContext(context))
 
+	metaData := meta.Get(context)
+	features := r.features
+	if value, ok := metaData["markdown"]; ok {
+		var err error
+		if features, err = frontMatterFeatures(r.features, value); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	printMode := r.isPrint(metaData)
+	if features == r.features && printMode == r.print {
+		return md, doc, context, nil
+	}
+
+	md = r.markdown(features, printMode)
+	context = parser.NewContext()
+	doc = md.Parser().Parse(text.NewReader(markdown), parser.WithContext(context))
+	return md, doc, context, nil
+}
+
+// Render converts Markdown to HTML, applying the theme template if configured.
+func (r *Renderer) Render(markdown []byte) ([]byte, error) {
+	doc, err := r.RenderDocument(markdown)
+	if err != nil {
+		return nil, err
+	}
+	return doc.HTML, nil
+}
+
+// RenderDocument converts Markdown to HTML like Render and also returns the
+// document title.
+func (r *Renderer) RenderDocument(markdown []byte) (*Document, error) {
+	md, doc, context, err := r.parse(markdown)
+	if err != nil {
+		return nil, err
+	}
+
 	// AST からタイトルを抽出
 	title, err := extractTitle(markdown, doc, context)
 	if err != nil {
@@ -73,20 +315,36 @@ func (r *Renderer) Render(markdown []byte) ([]byte, error) {
 
 	html := buf.Bytes()
 
-	if r.tmpl == nil {
-		return html, nil
+	metaData := meta.Get(context)
+	printMode := r.isPrint(metaData)
+	private, _ := metaData["private"].(bool)
+
+	themeName := r.resolveTheme(metaData, printMode)
+	if themeName == "" {
+		return &Document{HTML: html, Title: title, Print: printMode, Private: private}, nil
+	}
+
+	tmpl, err := r.template(themeName)
+	if err != nil {
+		return nil, err
 	}
 
 	var out bytes.Buffer
 	data := templateData{
-		Title:   title,
-		Content: template.HTML(html), //nolint:gosec // G203: HTML from markdown conversion is intentional
+		Title:             title,
+		Content:           template.HTML(html), //nolint:gosec // G203: HTML from markdown conversion is intentional
+		ColorScheme:       r.colorScheme,
+		ColorSchemeToggle: r.colorSchemeToggle,
+		Print:             printMode,
+	}
+	if printMode {
+		data.PrintCSS = template.CSS(r.printCSS) //nolint:gosec // G203: stylesheet is bundled or from trusted config
 	}
-	if err := r.tmpl.Execute(&out, data); err != nil {
+	if err := tmpl.Execute(&out, data); err != nil {
 		return nil, err
 	}
 
-	return out.Bytes(), nil
+	return &Document{HTML: out.Bytes(), Title: title, Theme: themeName, Print: printMode, Private: private}, nil
 }
 
 // extractTitle extracts the document title from markdown.
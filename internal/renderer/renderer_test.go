@@ -261,3 +261,317 @@ More text here.
 		}
 	})
 }
+
+func writeTheme(t *testing.T, configDir, name, content string) {
+	t.Helper()
+	themesDir := filepath.Join(configDir, "themes")
+	if err := os.MkdirAll(themesDir, 0755); err != nil { //nolint:gosec // G301: test directory
+		t.Fatal(err)
+	}
+	themeFile := filepath.Join(themesDir, name+".html")
+	if err := os.WriteFile(themeFile, []byte(content), 0644); err != nil { //nolint:gosec // G306: test file
+		t.Fatal(err)
+	}
+}
+
+func TestRender_ThemeSelection(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTheme(t, tmpDir, "base", "<base>{{.Content}}</base>")
+	writeTheme(t, tmpDir, "slides", "<slides>{{.Content}}</slides>")
+	writeTheme(t, tmpDir, "forced", "<forced>{{.Content}}</forced>")
+
+	frontMatterTheme := []byte("---\ntheme: slides\n---\n\n# Hello\n")
+
+	tests := []struct {
+		name     string
+		theme    string
+		opts     []Option
+		markdown []byte
+		want     string
+	}{
+		{
+			name:     "uses config theme without front-matter",
+			theme:    "base",
+			markdown: []byte("# Hello"),
+			want:     "<base>",
+		},
+		{
+			name:     "front-matter theme overrides config theme",
+			theme:    "base",
+			markdown: frontMatterTheme,
+			want:     "<slides>",
+		},
+		{
+			name:     "front-matter theme applies without config theme",
+			markdown: frontMatterTheme,
+			want:     "<slides>",
+		},
+		{
+			name:     "override theme wins over front-matter",
+			theme:    "base",
+			opts:     []Option{WithThemeOverride("forced")},
+			markdown: frontMatterTheme,
+			want:     "<forced>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer(tmpDir, tt.theme, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+
+			html, err := r.Render(tt.markdown)
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			if !strings.HasPrefix(string(html), tt.want) {
+				t.Errorf("Render() = %q, want prefix %q", string(html), tt.want)
+			}
+		})
+	}
+
+	t.Run("returns error when front-matter theme does not exist", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "")
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		_, err = r.Render([]byte("---\ntheme: missing\n---\n\n# Hello\n"))
+		if err == nil {
+			t.Error("Render() should return error when front-matter theme does not exist")
+		}
+	})
+
+	t.Run("returns error when override theme does not exist", func(t *testing.T) {
+		_, err := NewRenderer(tmpDir, "base", WithThemeOverride("missing"))
+		if err == nil {
+			t.Error("NewRenderer() should return error when override theme does not exist")
+		}
+	})
+}
+
+func TestRender_ColorScheme(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTheme(t, tmpDir, "scheme", `<html data-color-scheme="{{.ColorScheme}}">{{if .ColorSchemeToggle}}<button>{{end}}{{.Content}}</html>`)
+
+	t.Run("defaults to auto", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "scheme")
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), `data-color-scheme="auto"`) {
+			t.Errorf("Render() = %q, want auto color scheme", string(html))
+		}
+		if strings.Contains(string(html), "<button>") {
+			t.Errorf("Render() = %q, want no toggle", string(html))
+		}
+	})
+
+	t.Run("passes configured scheme and toggle", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "scheme", WithColorScheme("dark"), WithColorSchemeToggle(true))
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), `data-color-scheme="dark"`) {
+			t.Errorf("Render() = %q, want dark color scheme", string(html))
+		}
+		if !strings.Contains(string(html), "<button>") {
+			t.Errorf("Render() = %q, want toggle", string(html))
+		}
+	})
+
+	t.Run("built-in theme honors prefers-color-scheme", func(t *testing.T) {
+		r, err := NewRenderer(t.TempDir(), DefaultThemeName, WithColorSchemeToggle(true))
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		for _, want := range []string{"prefers-color-scheme: dark", "localStorage", "<h1>Hello</h1>"} {
+			if !strings.Contains(string(html), want) {
+				t.Errorf("Render() should contain %q", want)
+			}
+		}
+	})
+}
+
+func TestRender_Print(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTheme(t, tmpDir, "printable", `{{if .Print}}<style>{{.PrintCSS}}</style>{{end}}{{.Content}}`)
+
+	t.Run("front-matter media print applies bundled stylesheet", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "printable")
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("---\nmedia: print\n---\n\n# Hello\n"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), "@page") {
+			t.Errorf("Render() = %q, want paged-media stylesheet", string(html))
+		}
+	})
+
+	t.Run("printed documents get heading IDs", func(t *testing.T) {
+		for name, opts := range map[string][]Option{"front-matter": nil, "option": {WithPrint(true)}} {
+			r, err := NewRenderer(tmpDir, "printable", opts...)
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+			markdown := "# Getting Started\n"
+			if opts == nil {
+				markdown = "---\nmedia: print\n---\n" + markdown
+			}
+			doc, err := r.RenderDocument([]byte(markdown))
+			if err != nil {
+				t.Fatalf("RenderDocument() returned error: %v", err)
+			}
+			if !strings.Contains(string(doc.HTML), `<h1 id="getting-started">`) {
+				t.Errorf("%s: RenderDocument() = %q, want a heading ID", name, string(doc.HTML))
+			}
+			if !doc.Print {
+				t.Errorf("%s: Document.Print = false, want true", name)
+			}
+		}
+
+		r, err := NewRenderer(tmpDir, "printable")
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+		html, err := r.Render([]byte("# Getting Started\n"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if strings.Contains(string(html), " id=") {
+			t.Errorf("Render() = %q, want no heading ID on screen", string(html))
+		}
+	})
+
+	t.Run("screen documents do not get the stylesheet", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "printable")
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if strings.Contains(string(html), "<style>") {
+			t.Errorf("Render() = %q, want no stylesheet", string(html))
+		}
+	})
+
+	t.Run("custom print css replaces bundled stylesheet", func(t *testing.T) {
+		cssPath := filepath.Join(tmpDir, "custom.css")
+		if err := os.WriteFile(cssPath, []byte("body { color: red; }"), 0644); err != nil { //nolint:gosec // G306: test file
+			t.Fatal(err)
+		}
+
+		r, err := NewRenderer(tmpDir, "printable", WithPrint(true), WithPrintCSS(cssPath))
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), "<style>body { color: red; }</style>") {
+			t.Errorf("Render() = %q, want custom stylesheet", string(html))
+		}
+	})
+
+	t.Run("print mode without theme uses default theme with running header", func(t *testing.T) {
+		r, err := NewRenderer(tmpDir, "", WithPrint(true))
+		if err != nil {
+			t.Fatalf("NewRenderer() returned error: %v", err)
+		}
+
+		html, err := r.Render([]byte("# Hello"))
+		if err != nil {
+			t.Fatalf("Render() returned error: %v", err)
+		}
+		if !strings.Contains(string(html), `@top-center { content: "Hello"`) {
+			t.Errorf("Render() = %q, want running header with title", string(html))
+		}
+	})
+
+	t.Run("returns error when print css does not exist", func(t *testing.T) {
+		_, err := NewRenderer(tmpDir, "", WithPrintCSS(filepath.Join(tmpDir, "missing.css")))
+		if err == nil {
+			t.Error("NewRenderer() should return error when print css does not exist")
+		}
+	})
+}
+
+func TestRenderDocument(t *testing.T) {
+	r, err := NewRenderer("", "")
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	doc, err := r.RenderDocument([]byte("# Design Notes\n\nBody"))
+	if err != nil {
+		t.Fatalf("RenderDocument() returned error: %v", err)
+	}
+	if doc.Title != "Design Notes" {
+		t.Errorf("Title = %q, want %q", doc.Title, "Design Notes")
+	}
+	if doc.Theme != "" {
+		t.Errorf("Theme = %q, want empty without a theme", doc.Theme)
+	}
+	if !strings.Contains(string(doc.HTML), "<p>Body</p>") {
+		t.Errorf("HTML = %q, want rendered body", string(doc.HTML))
+	}
+}
+
+func TestRenderDocument_Private(t *testing.T) {
+	r, err := NewRenderer("", "")
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     bool
+	}{
+		{name: "no front-matter", markdown: "# Notes", want: false},
+		{name: "private true", markdown: "---\nprivate: true\n---\n# Notes", want: true},
+		{name: "private false", markdown: "---\nprivate: false\n---\n# Notes", want: false},
+		{name: "private not a bool", markdown: "---\nprivate: yes please\n---\n# Notes", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := r.RenderDocument([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("RenderDocument() returned error: %v", err)
+			}
+			if doc.Private != tt.want {
+				t.Errorf("Private = %v, want %v", doc.Private, tt.want)
+			}
+			if got := IsPrivate([]byte(tt.markdown)); got != tt.want {
+				t.Errorf("IsPrivate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package renderer
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark/ast"
+	gmrenderer "github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// newSanitizePolicy returns an allowlist close to what GitHub keeps of raw
+// HTML in Markdown: formatting and layout elements, images and links with
+// http, https, mailto and relative URLs, and no scripts, styles or event
+// handlers.
+func newSanitizePolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowElements("kbd", "samp", "var", "picture")
+	p.AllowAttrs("align").OnElements("div", "p", "h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("media", "srcset", "type", "width", "height").OnElements("source")
+	p.AllowAttrs("name").OnElements("a")
+	return p
+}
+
+var (
+	openingTagPattern = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9-]*)`)
+	closingTagPattern = regexp.MustCompile(`^</([A-Za-z][A-Za-z0-9-]*)\s*>$`)
+)
+
+// sanitizingRenderer renders raw HTML in Markdown through a sanitizer
+// instead of verbatim, and drops dangerous autolink URLs. The HTML that mdp
+// generates itself is not affected.
+//
+// Inline raw HTML reaches the renderer one tag at a time, so the renderer
+// remembers the elements opened in the current block and drops the closing
+// tag of every element whose opening tag the sanitizer dropped.
+type sanitizingRenderer struct {
+	policy *bluemonday.Policy
+	block  ast.Node
+	open   []openTag
+}
+
+// openTag is an element opened by inline raw HTML.
+type openTag struct {
+	name    string
+	dropped bool
+}
+
+func newSanitizingRenderer() gmrenderer.NodeRenderer {
+	return &sanitizingRenderer{policy: newSanitizePolicy()}
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r *sanitizingRenderer) RegisterFuncs(reg gmrenderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindAutoLink, r.renderAutoLink)
+}
+
+func (r *sanitizingRenderer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.HTMLBlock)
+	var buf bytes.Buffer
+	for i := range n.Lines().Len() {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		buf.Write(n.ClosureLine.Value(source))
+	}
+	_, _ = w.Write(r.policy.SanitizeBytes(buf.Bytes()))
+	return ast.WalkContinue, nil
+}
+
+func (r *sanitizingRenderer) renderRawHTML(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*ast.RawHTML)
+	var buf bytes.Buffer
+	for i := range n.Segments.Len() {
+		segment := n.Segments.At(i)
+		buf.Write(segment.Value(source))
+	}
+	raw := buf.Bytes()
+	sanitized := r.policy.SanitizeBytes(raw)
+
+	if block := enclosingBlock(n); block != r.block {
+		r.block, r.open = block, nil
+	}
+	if m := closingTagPattern.FindSubmatch(raw); m != nil {
+		if r.closeTag(strings.ToLower(string(m[1]))) {
+			return ast.WalkSkipChildren, nil
+		}
+	} else if m := openingTagPattern.FindSubmatch(raw); m != nil && !bytes.HasSuffix(raw, []byte("/>")) {
+		name := strings.ToLower(string(m[1]))
+		dropped := !bytes.HasPrefix(bytes.ToLower(sanitized), []byte("<"+name))
+		r.open = append(r.open, openTag{name: name, dropped: dropped})
+	}
+
+	_, _ = w.Write(sanitized)
+	return ast.WalkSkipChildren, nil
+}
+
+// closeTag closes the innermost open element named name and reports
+// whether its opening tag was dropped.
+func (r *sanitizingRenderer) closeTag(name string) bool {
+	for i := len(r.open) - 1; i >= 0; i-- {
+		if r.open[i].name == name {
+			dropped := r.open[i].dropped
+			r.open = r.open[:i]
+			return dropped
+		}
+	}
+	return false
+}
+
+// enclosingBlock returns the nearest block node containing n.
+func enclosingBlock(n ast.Node) ast.Node {
+	for n != nil && n.Type() != ast.TypeBlock {
+		n = n.Parent()
+	}
+	return n
+}
+
+// renderAutoLink renders autolinks such as <https://example.com> like
+// goldmark does, except that dangerous URLs are dropped as they are for
+// links and images. goldmark writes them as is even in safe mode.
+func (r *sanitizingRenderer) renderAutoLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.AutoLink)
+	url := n.URL(source)
+	_, _ = w.WriteString(`<a href="`)
+	if !html.IsDangerousURL(url) {
+		if n.AutoLinkType == ast.AutoLinkEmail && !bytes.HasPrefix(bytes.ToLower(url), []byte("mailto:")) {
+			_, _ = w.WriteString("mailto:")
+		}
+		_, _ = w.Write(util.EscapeHTML(util.URLEscape(url, false)))
+	}
+	_ = w.WriteByte('"')
+	if n.Attributes() != nil {
+		html.RenderAttributes(w, n, html.LinkAttributeFilter)
+	}
+	_ = w.WriteByte('>')
+	_, _ = w.Write(util.EscapeHTML(n.Label(source)))
+	_, _ = w.WriteString(`</a>`)
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,161 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_RawHTML(t *testing.T) {
+	markdown := strings.Join([]string{
+		"Press <kbd>Ctrl</kbd>+<kbd>C</kbd> and see<sup>1</sup>.",
+		"",
+		"<details open>",
+		"<summary>More</summary>",
+		"",
+		"Hidden **text**",
+		"",
+		"</details>",
+		"",
+		`<p align="center"><img src="logo.png" width="100" onerror="alert(1)"></p>`,
+		"",
+		`<script>alert(1)</script>`,
+		"",
+		`A <a href="javascript:alert(1)">bad</a> and a <a href="https://example.com">good</a> link.`,
+		"",
+		"- [x] done",
+	}, "\n")
+
+	tests := []struct {
+		name      string
+		policy    string
+		want      []string
+		forbidden []string
+	}{
+		{
+			name:      "omit by default",
+			policy:    "",
+			want:      []string{"<!-- raw HTML omitted -->", `<input checked="" disabled="" type="checkbox"`},
+			forbidden: []string{"<kbd>", "<script>"},
+		},
+		{
+			name:   "sanitize",
+			policy: "sanitize",
+			want: []string{
+				"<kbd>Ctrl</kbd>",
+				"<sup>1</sup>",
+				`<details open="">`,
+				"<summary>More</summary>",
+				"<strong>text</strong>",
+				`<p align="center"><img src="logo.png" width="100"></p>`,
+				// The closing tag of a dropped link is dropped with it.
+				`A bad and a <a href="https://example.com" rel="nofollow">good</a> link.`,
+				// Markup generated by mdp is kept.
+				`<input checked="" disabled="" type="checkbox"`,
+			},
+			forbidden: []string{"<script>", "onerror", "javascript:", "raw HTML omitted"},
+		},
+		{
+			name:      "allow",
+			policy:    "allow",
+			want:      []string{"<kbd>Ctrl</kbd>", "<script>alert(1)</script>", `onerror="alert(1)"`},
+			forbidden: []string{"raw HTML omitted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer("", "", WithRawHTML(tt.policy))
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+			html, err := r.Render([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Render() output does not contain %q\n%s", want, html)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(html), forbidden) {
+					t.Errorf("Render() output contains %q\n%s", forbidden, html)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_RawHTMLSanitizeMarkdownURLs(t *testing.T) {
+	r, err := NewRenderer("", "", WithRawHTML("sanitize"))
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{name: "link", markdown: "[x](javascript:alert(1))", want: `<a href="">x</a>`},
+		{name: "image", markdown: "![x](javascript:alert(1))", want: `<img src="" alt="x">`},
+		{name: "autolink", markdown: "<javascript:alert(1)>", want: `<a href="">javascript:alert(1)</a>`},
+		{name: "safe link", markdown: "[x](https://example.com)", want: `<a href="https://example.com">x</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			if !strings.Contains(string(html), tt.want) {
+				t.Errorf("Render() = %q, want it to contain %q", html, tt.want)
+			}
+			if strings.Contains(string(html), `="javascript:`) {
+				t.Errorf("Render() = %q, want no javascript: URL", html)
+			}
+		})
+	}
+}
+
+func TestRender_RawHTMLSanitizeClosingTags(t *testing.T) {
+	r, err := NewRenderer("", "", WithRawHTML("sanitize"))
+	if err != nil {
+		t.Fatalf("NewRenderer() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "dropped link",
+			markdown: `<a href="javascript:alert(1)">bad</a>`,
+			want:     "<p>bad</p>",
+		},
+		{
+			name:     "nested in kept elements",
+			markdown: `<em><a href="javascript:alert(1)">bad <b>bold</b></a></em>`,
+			want:     "<p><em>bad <b>bold</b></em></p>",
+		},
+		{
+			name:     "kept link inside dropped one",
+			markdown: `<a href="javascript:alert(1)">x <a href="https://example.com">y</a></a>`,
+			want:     `<p>x <a href="https://example.com" rel="nofollow">y</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			if got := strings.TrimSpace(string(html)); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
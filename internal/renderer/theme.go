@@ -0,0 +1,261 @@
+package renderer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+//go:embed themes/*.html
+var builtinThemes embed.FS
+
+// DefaultThemeName is the name of the built-in theme used to scaffold new themes.
+const DefaultThemeName = "default"
+
+// ErrThemeNotFound is returned when a theme exists neither in the user's
+// themes directory nor among the built-in themes.
+var ErrThemeNotFound = errors.New("theme not found")
+
+// Theme describes an available theme.
+type Theme struct {
+	Name string
+	// Path is the theme file path for user themes, or empty for built-in themes.
+	Path string
+	// Overrides reports whether a user theme shadows a built-in theme of the same name.
+	Overrides bool
+}
+
+// BuiltIn reports whether the theme is bundled with mdp.
+func (t Theme) BuiltIn() bool {
+	return t.Path == ""
+}
+
+func themesDir(configDir string) string {
+	return filepath.Join(configDir, "themes")
+}
+
+func isBuiltinTheme(name string) bool {
+	_, err := fs.Stat(builtinThemes, "themes/"+name+".html")
+	return err == nil
+}
+
+// LoadTheme returns the template source of the named theme.
+// A user theme in the themes directory under configDir takes precedence
+// over a built-in theme with the same name.
+func LoadTheme(configDir, name string) ([]byte, Theme, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, Theme{}, fmt.Errorf("invalid theme name: %q", name)
+	}
+
+	themePath := filepath.Join(themesDir(configDir), name+".html")
+	content, err := os.ReadFile(themePath) //nolint:gosec // G304: theme path is from trusted config
+	if err == nil {
+		return content, Theme{Name: name, Path: themePath, Overrides: isBuiltinTheme(name)}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, Theme{}, err
+	}
+
+	content, err = builtinThemes.ReadFile("themes/" + name + ".html")
+	if err != nil {
+		return nil, Theme{}, fmt.Errorf("%w: %q (looked in %s and built-in themes)", ErrThemeNotFound, name, themesDir(configDir))
+	}
+	return content, Theme{Name: name}, nil
+}
+
+// ListThemes returns the built-in themes and the user themes found under
+// configDir, sorted by name. A user theme that shadows a built-in theme is
+// listed once, as the user theme.
+func ListThemes(configDir string) ([]Theme, error) {
+	themes := make(map[string]Theme)
+
+	builtins, err := fs.Glob(builtinThemes, "themes/*.html")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range builtins {
+		name := strings.TrimSuffix(filepath.Base(path), ".html")
+		themes[name] = Theme{Name: name}
+	}
+
+	entries, err := os.ReadDir(themesDir(configDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".html" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".html")
+		_, builtin := themes[name]
+		themes[name] = Theme{
+			Name:      name,
+			Path:      filepath.Join(themesDir(configDir), entry.Name()),
+			Overrides: builtin,
+		}
+	}
+
+	list := make([]Theme, 0, len(themes))
+	for _, theme := range themes {
+		list = append(list, theme)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list, nil
+}
+
+// NewTheme scaffolds a user theme named name from the default built-in theme
+// and returns the path of the created file. It refuses to overwrite an
+// existing file.
+func NewTheme(configDir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid theme name: %q", name)
+	}
+
+	content, err := builtinThemes.ReadFile("themes/" + DefaultThemeName + ".html")
+	if err != nil {
+		return "", err
+	}
+
+	dir := themesDir(configDir)
+	if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gosec // G301: themes are not secret
+		return "", err
+	}
+
+	themePath := filepath.Join(dir, name+".html")
+	f, err := os.OpenFile(themePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644) //nolint:gosec // G302: themes are not secret
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("theme already exists: %s", themePath)
+		}
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return themePath, nil
+}
+
+// ValidateTheme parses the named theme and executes it against sample data.
+// It returns the theme and a list of problems found, such as references to
+// unknown fields or a missing {{.Content}}. A non-nil error means the theme
+// could not be loaded or parsed at all.
+func ValidateTheme(configDir, name string) (Theme, []string, error) {
+	content, theme, err := LoadTheme(configDir, name)
+	if err != nil {
+		return Theme{}, nil, err
+	}
+
+	tmpl, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return theme, nil, err
+	}
+
+	var problems []string
+	usesContent := false
+	known := templateFields()
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		walkFields(t.Tree.Root, func(node *parse.FieldNode) {
+			field := node.Ident[0]
+			if field == "Content" {
+				usesContent = true
+			}
+			if !known[field] {
+				location, _ := t.Tree.ErrorContext(node)
+				problems = append(problems, fmt.Sprintf("%s: unknown field .%s", location, field))
+			}
+		})
+	}
+
+	if !usesContent {
+		problems = append(problems, "{{.Content}} is never used; the rendered document will not appear")
+	}
+
+	// Unknown fields are already reported above; executing would only repeat
+	// the first of them.
+	if len(problems) == 0 {
+		if err := tmpl.Execute(io.Discard, sampleTemplateData()); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	return theme, problems, nil
+}
+
+// walkFields calls fn for every field reference evaluated against the
+// top-level template data. Fields inside range and with blocks are skipped
+// because dot refers to a different value there.
+func walkFields(node parse.Node, fn func(*parse.FieldNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, fn)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkFields(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkFields(arg, fn)
+		}
+	case *parse.FieldNode:
+		fn(n)
+	case *parse.IfNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.List, fn)
+		walkFields(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.ElseList, fn)
+	case *parse.WithNode:
+		walkFields(n.Pipe, fn)
+		walkFields(n.ElseList, fn)
+	}
+}
+
+// templateFields returns the set of field names available to theme templates.
+func templateFields() map[string]bool {
+	fields := make(map[string]bool)
+	typ := reflect.TypeFor[templateData]()
+	for i := range typ.NumField() {
+		fields[typ.Field(i).Name] = true
+	}
+	return fields
+}
+
+func sampleTemplateData() templateData {
+	return templateData{
+		Title:       "Sample Document",
+		Content:     template.HTML("<h1>Sample Document</h1>\n<p>Sample content.</p>\n"),
+		ColorScheme: "auto",
+		Print:       true,
+		PrintCSS:    template.CSS(defaultPrintCSS), //nolint:gosec // G203: bundled stylesheet
+	}
+}
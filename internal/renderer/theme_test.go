@@ -0,0 +1,144 @@
+package renderer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	t.Run("loads built-in theme", func(t *testing.T) {
+		content, theme, err := LoadTheme(t.TempDir(), DefaultThemeName)
+		if err != nil {
+			t.Fatalf("LoadTheme() returned error: %v", err)
+		}
+		if !theme.BuiltIn() {
+			t.Errorf("LoadTheme() theme should be built-in, got path %q", theme.Path)
+		}
+		if !strings.Contains(string(content), "{{.Content}}") {
+			t.Errorf("LoadTheme() content should contain {{.Content}}, got %q", content)
+		}
+	})
+
+	t.Run("user theme overrides built-in theme", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeTheme(t, tmpDir, DefaultThemeName, "<user>{{.Content}}</user>")
+
+		content, theme, err := LoadTheme(tmpDir, DefaultThemeName)
+		if err != nil {
+			t.Fatalf("LoadTheme() returned error: %v", err)
+		}
+		if string(content) != "<user>{{.Content}}</user>" {
+			t.Errorf("LoadTheme() content = %q, want user theme", content)
+		}
+		if !theme.Overrides {
+			t.Error("LoadTheme() theme should be marked as overriding built-in")
+		}
+	})
+
+	t.Run("returns ErrThemeNotFound for unknown theme", func(t *testing.T) {
+		_, _, err := LoadTheme(t.TempDir(), "nonexistent")
+		if !errors.Is(err, ErrThemeNotFound) {
+			t.Errorf("LoadTheme() error = %v, want ErrThemeNotFound", err)
+		}
+	})
+
+	t.Run("rejects theme name with path separator", func(t *testing.T) {
+		_, _, err := LoadTheme(t.TempDir(), "../secret")
+		if err == nil {
+			t.Error("LoadTheme() should return error for theme name with path separator")
+		}
+	})
+}
+
+func TestListThemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTheme(t, tmpDir, "custom", "{{.Content}}")
+	writeTheme(t, tmpDir, DefaultThemeName, "{{.Content}}")
+
+	themes, err := ListThemes(tmpDir)
+	if err != nil {
+		t.Fatalf("ListThemes() returned error: %v", err)
+	}
+
+	if len(themes) != 2 {
+		t.Fatalf("ListThemes() returned %d themes, want 2: %+v", len(themes), themes)
+	}
+	if themes[0].Name != "custom" || themes[0].BuiltIn() || themes[0].Overrides {
+		t.Errorf("ListThemes()[0] = %+v, want user theme custom", themes[0])
+	}
+	if themes[1].Name != DefaultThemeName || themes[1].BuiltIn() || !themes[1].Overrides {
+		t.Errorf("ListThemes()[1] = %+v, want user theme overriding default", themes[1])
+	}
+}
+
+func TestNewTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path, err := NewTheme(tmpDir, "mine")
+	if err != nil {
+		t.Fatalf("NewTheme() returned error: %v", err)
+	}
+	if path != filepath.Join(tmpDir, "themes", "mine.html") {
+		t.Errorf("NewTheme() path = %q", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("NewTheme() did not create file: %v", err)
+	}
+
+	if _, err := NewTheme(tmpDir, "mine"); err == nil {
+		t.Error("NewTheme() should refuse to overwrite an existing theme")
+	}
+}
+
+func TestValidateTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTheme(t, tmpDir, "typo", "<title>{{.Title}}</title>\n<body>{{.Contnet}}</body>")
+	writeTheme(t, tmpDir, "nested", "{{range .Items}}{{.Name}}{{end}}{{.Content}}")
+	writeTheme(t, tmpDir, "broken", "{{.Content}")
+
+	t.Run("built-in theme is valid", func(t *testing.T) {
+		_, problems, err := ValidateTheme(tmpDir, DefaultThemeName)
+		if err != nil {
+			t.Fatalf("ValidateTheme() returned error: %v", err)
+		}
+		if len(problems) != 0 {
+			t.Errorf("ValidateTheme() problems = %v, want none", problems)
+		}
+	})
+
+	t.Run("reports unknown field and missing content", func(t *testing.T) {
+		_, problems, err := ValidateTheme(tmpDir, "typo")
+		if err != nil {
+			t.Fatalf("ValidateTheme() returned error: %v", err)
+		}
+		if len(problems) != 2 {
+			t.Fatalf("ValidateTheme() problems = %v, want 2", problems)
+		}
+		if !strings.Contains(problems[0], "typo:2:") || !strings.Contains(problems[0], "unknown field .Contnet") {
+			t.Errorf("ValidateTheme() problems[0] = %q", problems[0])
+		}
+		if !strings.Contains(problems[1], "{{.Content}}") {
+			t.Errorf("ValidateTheme() problems[1] = %q", problems[1])
+		}
+	})
+
+	t.Run("reports fields used as range pipeline only", func(t *testing.T) {
+		_, problems, err := ValidateTheme(tmpDir, "nested")
+		if err != nil {
+			t.Fatalf("ValidateTheme() returned error: %v", err)
+		}
+		if len(problems) != 1 || !strings.Contains(problems[0], "unknown field .Items") {
+			t.Errorf("ValidateTheme() problems = %v, want only .Items", problems)
+		}
+	})
+
+	t.Run("returns error for unparsable theme", func(t *testing.T) {
+		_, _, err := ValidateTheme(tmpDir, "broken")
+		if err == nil {
+			t.Error("ValidateTheme() should return error for unparsable theme")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// tocMarker is the comment that marks the list after it as a table of
+// contents.
+var tocMarker = []byte("<!-- toc -->")
+
+// tocTransformer gives the list that follows a <!-- toc --> comment the
+// "toc" class, which the print stylesheet adds page numbers to, and removes
+// the comment. It works whatever the raw HTML policy is.
+type tocTransformer struct{}
+
+// Transform implements parser.ASTTransformer.
+func (tocTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+	var markers []*ast.HTMLBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := n.(*ast.HTMLBlock); ok && entering && isTOCMarker(block, source) {
+			markers = append(markers, block)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, marker := range markers {
+		list, ok := marker.NextSibling().(*ast.List)
+		if !ok {
+			continue
+		}
+		list.SetAttributeString("class", []byte("toc"))
+		marker.Parent().RemoveChild(marker.Parent(), marker)
+	}
+}
+
+// isTOCMarker reports whether block is a <!-- toc --> comment alone.
+func isTOCMarker(block *ast.HTMLBlock, source []byte) bool {
+	if block.HTMLBlockType != ast.HTMLBlockType2 {
+		return false
+	}
+	var buf bytes.Buffer
+	for i := range block.Lines().Len() {
+		line := block.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	if block.HasClosure() {
+		buf.Write(block.ClosureLine.Value(source))
+	}
+	return bytes.EqualFold(bytes.TrimSpace(buf.Bytes()), tocMarker)
+}
+
+// toc is the goldmark extension for tables of contents.
+type toc struct{}
+
+// Extend implements goldmark.Extender.
+func (toc) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(tocTransformer{}, 100)))
+}
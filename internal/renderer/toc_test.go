@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_TOC(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		markdown  string
+		want      []string
+		forbidden []string
+	}{
+		{
+			name:      "marked list",
+			markdown:  "<!-- toc -->\n- [Intro](#intro)\n- [Usage](#usage)\n\n# Intro",
+			want:      []string{`<ul class="toc">`, `<a href="#intro">Intro</a>`},
+			forbidden: []string{"toc -->", "raw HTML omitted"},
+		},
+		{
+			name:      "marked list with sanitize",
+			policy:    "sanitize",
+			markdown:  "<!-- TOC -->\n1. [Intro](#intro)",
+			want:      []string{`<ol class="toc">`},
+			forbidden: []string{"TOC -->"},
+		},
+		{
+			name:      "marker without a list",
+			markdown:  "<!-- toc -->\n\nText",
+			want:      []string{"<!-- raw HTML omitted -->", "<p>Text</p>"},
+			forbidden: []string{`class="toc"`},
+		},
+		{
+			name:      "unmarked list",
+			markdown:  "- [Intro](#intro)",
+			forbidden: []string{`class="toc"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer("", "", WithRawHTML(tt.policy))
+			if err != nil {
+				t.Fatalf("NewRenderer() returned error: %v", err)
+			}
+			html, err := r.Render([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Render() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Render() = %q, want it to contain %q", html, want)
+				}
+			}
+			for _, forbidden := range tt.forbidden {
+				if strings.Contains(string(html), forbidden) {
+					t.Errorf("Render() = %q, want it not to contain %q", html, forbidden)
+				}
+			}
+		})
+	}
+}
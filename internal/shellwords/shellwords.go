@@ -0,0 +1,104 @@
+// Package shellwords splits and joins command lines using POSIX shell
+// quoting rules, without performing any expansion.
+package shellwords
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnterminatedQuote is returned by Split when a quote is not closed.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// ErrTrailingBackslash is returned by Split when the input ends with an
+// unescaped backslash.
+var ErrTrailingBackslash = errors.New("trailing backslash")
+
+// Split splits s into words. Words are separated by unquoted whitespace.
+// Single quotes preserve everything up to the closing quote, double quotes
+// allow backslash escapes of ", \, $ and `, and a backslash outside quotes
+// escapes the next character.
+func Split(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 >= len(runes) {
+				return nil, ErrTrailingBackslash
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case c == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, ErrUnterminatedQuote
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, ErrUnterminatedQuote
+			}
+			inWord = true
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Join quotes words as needed and joins them with spaces, so that Split
+// returns the original words.
+func Join(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = Quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote returns word quoted for a POSIX shell if it contains characters
+// that would otherwise be interpreted.
+func Quote(word string) string {
+	if word == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(word, " \t\n\r'\"\\$`|&;<>()*?[]#~!") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
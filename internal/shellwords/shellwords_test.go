@@ -0,0 +1,79 @@
+package shellwords
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "only whitespace", input: " \t ", want: nil},
+		{name: "single word", input: "firefox", want: []string{"firefox"}},
+		{name: "multiple words", input: "firefox --new-window  {url}", want: []string{"firefox", "--new-window", "{url}"}},
+		{name: "single quotes", input: `open -a 'Google Chrome'`, want: []string{"open", "-a", "Google Chrome"}},
+		{name: "single quotes keep backslashes", input: `'a\b'`, want: []string{`a\b`}},
+		{name: "double quotes", input: `open -a "Google Chrome"`, want: []string{"open", "-a", "Google Chrome"}},
+		{name: "double quotes with escapes", input: `"say \"hi\" \n"`, want: []string{`say "hi" \n`}},
+		{name: "backslash escapes space", input: `/Applications/Google\ Chrome`, want: []string{"/Applications/Google Chrome"}},
+		{name: "adjacent quoted parts join", input: `a'b c'"d"`, want: []string{"ab cd"}},
+		{name: "empty quoted word", input: `cmd ''`, want: []string{"cmd", ""}},
+		{name: "unterminated single quote", input: `'abc`, wantErr: ErrUnterminatedQuote},
+		{name: "unterminated double quote", input: `"abc`, wantErr: ErrUnterminatedQuote},
+		{name: "trailing backslash", input: `abc\`, wantErr: ErrTrailingBackslash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Split(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Split(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Split(%q) returned error: %v", tt.input, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{name: "plain words", words: []string{"firefox", "--new-window"}, want: "firefox --new-window"},
+		{name: "word with space", words: []string{"open", "-a", "Google Chrome"}, want: "open -a 'Google Chrome'"},
+		{name: "word with single quote", words: []string{"it's"}, want: `'it'\''s'`},
+		{name: "empty word", words: []string{"cmd", ""}, want: "cmd ''"},
+		{name: "placeholder", words: []string{"firefox", "{url}"}, want: "firefox {url}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Join(tt.words)
+			if got != tt.want {
+				t.Errorf("Join(%q) = %q, want %q", tt.words, got, tt.want)
+			}
+			roundTrip, err := Split(got)
+			if err != nil {
+				t.Fatalf("Split(%q) returned error: %v", got, err)
+			}
+			if !slices.Equal(roundTrip, tt.words) {
+				t.Errorf("Split(Join(%q)) = %q", tt.words, roundTrip)
+			}
+		})
+	}
+}